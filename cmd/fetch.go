@@ -30,6 +30,8 @@ var templateFuncMap = template.FuncMap{
 	"white":       lib.White,
 	"uniquecolor": lib.Unique,
 	"colorlevel":  lib.ColorLevel,
+	"level":       lib.LevelName,
+	"json":        lib.JSON,
 }
 
 var (
@@ -41,6 +43,9 @@ var (
 	verbose       bool
 	raw           bool
 	maxStreams    int
+	multiline     int
+	grokPattern   string
+	parsers       []string
 )
 
 // Error messages
@@ -67,6 +72,9 @@ func init() {
 	fetchCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Verbose log output (includes log context in data fields)")
 	fetchCmd.Flags().BoolVarP(&raw, "raw", "r", false, "Raw JSON output")
 	fetchCmd.Flags().IntVarP(&maxStreams, "max-streams", "m", 100, "Maximum number of streams to fetch from (for prefix search)")
+	fetchCmd.Flags().StringSliceVarP(&parsers, "parser", "p", nil, "Additional message formats to parse (alb, w3c, postgres-csv, lambda-report)")
+	fetchCmd.Flags().StringVar(&grokPattern, "grok", "", "Grok pattern for extracting data fields from unstructured messages (e.g. '%{IP:client} %{NUMBER:status}')")
+	fetchCmd.Flags().IntVar(&multiline, "multiline", 0, "Join pretty printed JSON events spanning up to this many lines (0 to disable)")
 }
 
 func fetch(cmd *cobra.Command, args []string) error {
@@ -95,6 +103,23 @@ func fetch(cmd *cobra.Command, args []string) error {
 	}
 
 	lib.SetMaxStreams(maxStreams)
+	lib.SetMaxMultilineLines(multiline)
+
+	for _, name := range parsers {
+		parser, err := lib.NewParser(name)
+		if err != nil {
+			return err
+		}
+		lib.SetParsers(append(lib.Parsers, parser)...)
+	}
+
+	if grokPattern != "" {
+		grok, err := lib.NewGrokParser(grokPattern)
+		if err != nil {
+			return fmt.Errorf("Failed to parse grok pattern: %s", err)
+		}
+		lib.SetParsers(append(lib.Parsers, grok.Parse)...)
+	}
 
 	logReader, err := lib.NewCloudwatchLogsReader(args[0], task, start, end)
 	if err != nil {
@@ -0,0 +1,141 @@
+package lib
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// albRequestTypes are the values of the first field of an ALB access log entry
+var albRequestTypes = map[string]bool{
+	"http":  true,
+	"https": true,
+	"h2":    true,
+	"grpcs": true,
+	"ws":    true,
+	"wss":   true,
+}
+
+// albLayout gives the positions of the fields ParseALB uses in an access log
+// entry
+type albLayout struct {
+	time       int
+	client     int
+	processing int
+	status     int
+	request    int
+}
+
+var (
+	albLayoutApplication = albLayout{time: 1, client: 3, processing: 5, status: 8, request: 12}
+	// Classic entries have no request type, so each field is one earlier
+	albLayoutClassic = albLayout{time: 0, client: 2, processing: 4, status: 7, request: 11}
+)
+
+// ParseALB parses Application and Classic Load Balancer access log entries.
+// The request line is used as the message, the level is based on the status
+// code returned by the load balancer, and the client IP, processing times and
+// status codes are placed in Data as numbers.  Classic entries' backend times
+// and status codes are given the same names as an ALB's target ones.
+func ParseALB(stream, message string) (SlogEvent, bool) {
+	fields, ok := splitQuoted(message)
+	if !ok || len(fields) < 12 {
+		return SlogEvent{}, false
+	}
+
+	layout := albLayoutClassic
+	if albRequestTypes[fields[0]] {
+		layout = albLayoutApplication
+	}
+	if len(fields) <= layout.request {
+		return SlogEvent{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, fields[layout.time])
+	if err != nil {
+		return SlogEvent{}, false
+	}
+
+	clientIP := fields[layout.client]
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		clientIP = host
+	}
+
+	data := map[string]interface{}{
+		"client_ip": clientIP,
+		"request":   fields[layout.request],
+	}
+	for ix, key := range []string{"request_processing_time", "target_processing_time"} {
+		if v, err := strconv.ParseFloat(fields[layout.processing+ix], 64); err == nil {
+			data[key] = v
+		}
+	}
+	for ix, key := range []string{"elb_status_code", "target_status_code"} {
+		if v, err := strconv.Atoi(fields[layout.status+ix]); err == nil {
+			data[key] = v
+		}
+	}
+
+	level := ecslogs.INFO
+	if status, ok := data["elb_status_code"].(int); ok {
+		switch {
+		case status >= 500:
+			level = ecslogs.ERROR
+		case status >= 400:
+			level = ecslogs.WARN
+		}
+	}
+
+	return SlogEvent{
+		Level:   level,
+		Time:    t,
+		Message: fields[layout.request],
+		Data:    data,
+	}, true
+}
+
+// splitQuoted splits s on spaces, treating double quoted strings (which may
+// contain escaped quotes) as a single field
+func splitQuoted(s string) ([]string, bool) {
+	var fields []string
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return fields, true
+		}
+
+		if s[0] != '"' {
+			end := strings.IndexByte(s, ' ')
+			if end < 0 {
+				end = len(s)
+			}
+			fields = append(fields, s[:end])
+			s = s[end:]
+			continue
+		}
+
+		var field strings.Builder
+		closed := false
+		ix := 1
+		for ; ix < len(s); ix++ {
+			if s[ix] == '\\' && ix+1 < len(s) {
+				ix++
+				field.WriteByte(s[ix])
+				continue
+			}
+			if s[ix] == '"' {
+				closed = true
+				break
+			}
+			field.WriteByte(s[ix])
+		}
+		if !closed {
+			return nil, false
+		}
+		fields = append(fields, field.String())
+		s = s[ix+1:]
+	}
+}
@@ -0,0 +1,69 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func TestParseALB(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		level   ecslogs.Level
+		time    time.Time
+		request string
+		target  float64
+		status  int
+	}{
+		{
+			name:    "application",
+			line:    `https 2018-07-02T22:23:00.186641Z app/my-loadbalancer/50dc6c495c0c9188 192.168.131.39:2817 10.0.0.1:80 0.086 0.048 0.037 502 200 0 57 "GET https://www.example.com:443/ HTTP/1.1" "curl/7.46.0" ECDHE-RSA-AES128-GCM-SHA256 TLSv1.2 arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067 "Root=1-58337281-1d84f3d73c47ec4e58577259" "www.example.com" "arn:aws:acm:us-east-2:123456789012:certificate/12345678-1234-1234-1234-123456789012" 1 2018-07-02T22:22:48.364000Z "authenticate,forward" "-" "-" "10.0.0.1:80" "200" "-" "-"`,
+			level:   ecslogs.ERROR,
+			time:    time.Date(2018, 7, 2, 22, 23, 0, 186641000, time.UTC),
+			request: "GET https://www.example.com:443/ HTTP/1.1",
+			target:  0.048,
+			status:  200,
+		},
+		{
+			name:    "classic",
+			line:    `2015-05-13T23:39:43.945958Z my-loadbalancer 192.168.131.39:2817 10.0.0.1:80 0.000073 0.001048 0.000057 404 404 0 29 "GET http://www.example.com:80/ HTTP/1.1" "curl/7.38.0" - -`,
+			level:   ecslogs.WARN,
+			time:    time.Date(2015, 5, 13, 23, 39, 43, 945958000, time.UTC),
+			request: "GET http://www.example.com:80/ HTTP/1.1",
+			target:  0.001048,
+			status:  404,
+		},
+	}
+
+	for _, test := range tests {
+		e, ok := ParseALB("s", test.line)
+		if !ok {
+			t.Errorf("%s: failed to parse", test.name)
+			continue
+		}
+		if e.Level != test.level {
+			t.Errorf("%s: Level = %s, want %s", test.name, e.Level, test.level)
+		}
+		if !e.Time.Equal(test.time) {
+			t.Errorf("%s: Time = %s, want %s", test.name, e.Time, test.time)
+		}
+		if e.Message != test.request {
+			t.Errorf("%s: Message = %q, want %q", test.name, e.Message, test.request)
+		}
+		if e.Data["client_ip"] != "192.168.131.39" {
+			t.Errorf("%s: client_ip = %v", test.name, e.Data["client_ip"])
+		}
+		if e.Data["target_processing_time"] != test.target {
+			t.Errorf("%s: target_processing_time = %v, want %v", test.name, e.Data["target_processing_time"], test.target)
+		}
+		if e.Data["target_status_code"] != test.status {
+			t.Errorf("%s: target_status_code = %v, want %v", test.name, e.Data["target_status_code"], test.status)
+		}
+	}
+
+	if _, ok := ParseALB("s", "GET / HTTP/1.1 200"); ok {
+		t.Error("parsed a line which isn't an access log entry")
+	}
+}
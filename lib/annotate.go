@@ -0,0 +1,113 @@
+package lib
+
+import (
+	"sort"
+	"time"
+)
+
+// CategoryRule assigns events matching a predicate to the named category
+type CategoryRule struct {
+	Name  string
+	Match func(Event) bool
+}
+
+// UncategorizedCategory is the category given to events matching no rule
+const UncategorizedCategory = "uncategorized"
+
+// Categorize returns a copy of events with Data["_category"] set to the name of
+// the first rule that matches each event, or "uncategorized" if none do
+func Categorize(events []Event, rules []CategoryRule) []Event {
+	categorized := make([]Event, 0, len(events))
+	for _, e := range events {
+		e = e.Parsed()
+		category := UncategorizedCategory
+		for _, rule := range rules {
+			if rule.Match(e) {
+				category = rule.Name
+				break
+			}
+		}
+		categorized = append(categorized, withData(e, "_category", category))
+	}
+	return categorized
+}
+
+// CollapseAcrossStreams merges events with the same Fingerprint that were
+// created within window of the first such event, regardless of which stream
+// they came from.  Each returned event is the earliest of its group, with the
+// sorted names of the streams the group appeared on in Data["_streams"].
+// Events are returned in creation time order.
+func CollapseAcrossStreams(events []Event, window time.Duration) []Event {
+	sorted := make([]Event, len(events))
+	for ix, e := range events {
+		sorted[ix] = e.Parsed()
+	}
+	sort.Stable(ByCreationTime(sorted))
+
+	type group struct {
+		event   Event
+		streams map[string]bool
+	}
+	var groups []*group
+	open := map[string]*group{}
+
+	for _, e := range sorted {
+		fingerprint := e.Fingerprint()
+		if g, ok := open[fingerprint]; ok && e.CreationTime.Sub(g.event.CreationTime) <= window {
+			g.streams[e.Stream] = true
+			continue
+		}
+		g := &group{
+			event:   e,
+			streams: map[string]bool{e.Stream: true},
+		}
+		groups = append(groups, g)
+		open[fingerprint] = g
+	}
+
+	collapsed := make([]Event, 0, len(groups))
+	for _, g := range groups {
+		streams := make([]string, 0, len(g.streams))
+		for stream := range g.streams {
+			streams = append(streams, stream)
+		}
+		sort.Strings(streams)
+		collapsed = append(collapsed, withData(g.event, "_streams", streams))
+	}
+	return collapsed
+}
+
+// AnnotateRelativeTo returns the events created within window of pivot, with
+// Data["_relpos"] set to "before", "at" or "after" based on when each event
+// was created relative to the pivot
+func AnnotateRelativeTo(events []Event, pivot time.Time, window time.Duration) []Event {
+	annotated := []Event{}
+	for _, e := range events {
+		offset := e.CreationTime.Sub(pivot)
+		if offset < -window || offset > window {
+			continue
+		}
+
+		position := "at"
+		if offset < 0 {
+			position = "before"
+		} else if offset > 0 {
+			position = "after"
+		}
+		annotated = append(annotated, withData(e, "_relpos", position))
+	}
+	return annotated
+}
+
+// withData returns a copy of e with the data field key set to value, leaving
+// the original event's Data untouched
+func withData(e Event, key string, value interface{}) Event {
+	e = e.Parsed()
+	data := make(map[string]interface{}, len(e.Data)+1)
+	for k, v := range e.Data {
+		data[k] = v
+	}
+	data[key] = value
+	e.Data = data
+	return e
+}
@@ -0,0 +1,102 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCategorize(t *testing.T) {
+	events := []Event{
+		{ID: "1", SlogEvent: SlogEvent{Message: "database timeout", Data: map[string]interface{}{"k": "v"}}},
+		{ID: "2", SlogEvent: SlogEvent{Message: "request timeout"}},
+		{ID: "3", SlogEvent: SlogEvent{Message: "started"}},
+	}
+	rules := []CategoryRule{
+		{Name: "database", Match: func(e Event) bool { return strings.HasPrefix(e.Message, "database") }},
+		{Name: "timeout", Match: func(e Event) bool { return strings.Contains(e.Message, "timeout") }},
+	}
+
+	categorized := Categorize(events, rules)
+	want := []string{"database", "timeout", UncategorizedCategory}
+	if len(categorized) != len(want) {
+		t.Fatalf("Expected %d events, got %d", len(want), len(categorized))
+	}
+	for ix, category := range want {
+		if got := categorized[ix].Data["_category"]; got != category {
+			t.Errorf("Event %s category = %v, want %s", categorized[ix].ID, got, category)
+		}
+	}
+	if categorized[0].Data["k"] != "v" {
+		t.Errorf("Expected existing data to be kept, got %v", categorized[0].Data)
+	}
+	if _, ok := events[0].Data["_category"]; ok {
+		t.Error("Expected the original event's data to be left untouched")
+	}
+}
+
+func TestCollapseAcrossStreams(t *testing.T) {
+	base := time.Unix(60000, 0)
+	event := func(id, stream string, offset time.Duration, message string) Event {
+		return Event{ID: id, Stream: stream, CreationTime: base.Add(offset), SlogEvent: SlogEvent{Message: message}}
+	}
+	events := []Event{
+		event("1", "c", 0, "deploy started"),
+		event("2", "a", time.Millisecond, "deploy started"),
+		event("3", "b", 2*time.Millisecond, "deploy started"),
+		event("4", "a", time.Millisecond, "other"),
+		// outside the window of the first, so it starts a new group
+		event("5", "b", time.Minute, "deploy started"),
+	}
+
+	collapsed := CollapseAcrossStreams(events, time.Second)
+	want := []struct {
+		id      string
+		streams string
+	}{
+		{"1", "[a b c]"},
+		{"4", "[a]"},
+		{"5", "[b]"},
+	}
+	if len(collapsed) != len(want) {
+		t.Fatalf("Expected %d events, got %d", len(want), len(collapsed))
+	}
+	for ix, w := range want {
+		e := collapsed[ix]
+		if e.ID != w.id || fmt.Sprint(e.Data["_streams"]) != w.streams {
+			t.Errorf("Event %d = %s on %v, want %s on %s", ix, e.ID, e.Data["_streams"], w.id, w.streams)
+		}
+	}
+}
+
+func TestAnnotateRelativeTo(t *testing.T) {
+	pivot := time.Unix(60000, 0)
+	event := func(id string, offset time.Duration) Event {
+		return Event{ID: id, CreationTime: pivot.Add(offset)}
+	}
+	events := []Event{
+		event("early", -time.Hour),
+		event("before", -time.Minute),
+		event("at", 0),
+		event("after", time.Second),
+		event("edge", 5*time.Minute),
+		event("late", time.Hour),
+	}
+
+	annotated := AnnotateRelativeTo(events, pivot, 5*time.Minute)
+	want := []struct{ id, position string }{
+		{"before", "before"},
+		{"at", "at"},
+		{"after", "after"},
+		{"edge", "after"},
+	}
+	if len(annotated) != len(want) {
+		t.Fatalf("Expected %d events within the window, got %d", len(want), len(annotated))
+	}
+	for ix, w := range want {
+		if e := annotated[ix]; e.ID != w.id || e.Data["_relpos"] != w.position {
+			t.Errorf("Event %d = %s %v, want %s %s", ix, e.ID, e.Data["_relpos"], w.id, w.position)
+		}
+	}
+}
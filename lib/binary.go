@@ -0,0 +1,86 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func init() {
+	// Concrete types which can be held in Data, so gob can encode them as
+	// interface values
+	gob.Register(json.Number(""))
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// binaryEvent is the representation of an event encoded by MarshalBinary
+type binaryEvent struct {
+	Level        ecslogs.Level
+	Time         time.Time
+	Source       SourceInfo
+	Message      string
+	Data         map[string]interface{}
+	Stream       string
+	Group        string
+	ID           string
+	IngestTime   time.Time
+	CreationTime time.Time
+	Seq          int
+	Index        int64
+	Structured   bool
+}
+
+// MarshalBinary encodes the event in a compact binary (gob) format, for
+// passing events between processes.  Data values keep their types, including
+// nested objects and arrays.
+func (e Event) MarshalBinary() ([]byte, error) {
+	e = e.Parsed()
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(binaryEvent{
+		Level:        e.Level,
+		Time:         e.Time,
+		Source:       e.Source,
+		Message:      e.Message,
+		Data:         e.Data,
+		Stream:       e.Stream,
+		Group:        e.Group,
+		ID:           e.ID,
+		IngestTime:   e.IngestTime,
+		CreationTime: e.CreationTime,
+		Seq:          e.Seq,
+		Index:        e.Index,
+		Structured:   e.structured,
+	})
+	return buf.Bytes(), err
+}
+
+// UnmarshalBinary decodes an event encoded by MarshalBinary
+func (e *Event) UnmarshalBinary(data []byte) error {
+	var b binaryEvent
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&b); err != nil {
+		return err
+	}
+
+	*e = Event{
+		SlogEvent: SlogEvent{
+			Level:   b.Level,
+			Time:    b.Time,
+			Source:  b.Source,
+			Message: b.Message,
+			Data:    b.Data,
+		},
+		Stream:       b.Stream,
+		Group:        b.Group,
+		ID:           b.ID,
+		IngestTime:   b.IngestTime,
+		CreationTime: b.CreationTime,
+		Seq:          b.Seq,
+		Index:        b.Index,
+		structured:   b.Structured,
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package lib
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	e := Event{
+		ID:           "1",
+		Group:        "g",
+		Stream:       "s",
+		CreationTime: ts,
+		IngestTime:   ts.Add(time.Second),
+		Seq:          3,
+		structured:   true,
+		SlogEvent: SlogEvent{
+			Message: "m",
+			Time:    ts,
+			Data: map[string]interface{}{
+				"big":     json.Number("12345678901234567890"),
+				"cached":  true,
+				"user":    "x",
+				"request": map[string]interface{}{"tags": []interface{}{json.Number("1"), "two", nil}},
+			},
+		},
+	}
+
+	encoded, err := e.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Event
+	if err := decoded.UnmarshalBinary(encoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, e) {
+		t.Errorf("Decoded %#v, want %#v", decoded, e)
+	}
+
+	if err := decoded.UnmarshalBinary([]byte("not gob")); err == nil {
+		t.Error("Expected an error decoding invalid data")
+	}
+}
@@ -0,0 +1,76 @@
+package lib
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/hashicorp/golang-lru"
+)
+
+// parsedEvents memoizes the events built by NewEvent, keyed by log group and
+// event ID.  It is nil when caching is disabled.
+var parsedEvents *lru.Cache
+
+type parsedEvent struct {
+	event    Event
+	flatOnce sync.Once
+	flat     map[string]interface{}
+}
+
+// SetEventCacheSize sets the number of parsed events to keep in memory, so
+// that building the same event again (e.g. when re-rendering a fetched set)
+// skips parsing and flattening.  A size of 0 disables the cache, which is the
+// default.
+//
+// Cached events share their Data map, so callers modifying Data should leave
+// the cache disabled.  Changing how events are parsed (with SetParsers,
+// SetGroupParsers or SetLazyParsing) empties the cache.
+func SetEventCacheSize(size int) error {
+	if size <= 0 {
+		parsedEvents = nil
+		return nil
+	}
+
+	cache, err := lru.New(size)
+	if err != nil {
+		return err
+	}
+	parsedEvents = cache
+	return nil
+}
+
+// holds reports whether e is the cached event, rather than a different event
+// built with the same ID
+func (p *parsedEvent) holds(e Event) bool {
+	return reflect.ValueOf(e.Data).Pointer() == reflect.ValueOf(p.event.Data).Pointer()
+}
+
+func parsedEventKey(group, id string) string {
+	return group + "\x00" + id
+}
+
+func lookupParsedEvent(group, id string) (*parsedEvent, bool) {
+	cache := parsedEvents
+	if cache == nil {
+		return nil, false
+	}
+	entry, ok := cache.Get(parsedEventKey(group, id))
+	if !ok {
+		return nil, false
+	}
+	return entry.(*parsedEvent), true
+}
+
+func cacheParsedEvent(e Event) {
+	if cache := parsedEvents; cache != nil {
+		cache.Add(parsedEventKey(e.Group, e.ID), &parsedEvent{event: e})
+	}
+}
+
+// purgeParsedEvents empties the cache, for when events would now be parsed
+// differently
+func purgeParsedEvents() {
+	if cache := parsedEvents; cache != nil {
+		cache.Purge()
+	}
+}
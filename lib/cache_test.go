@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"testing"
+)
+
+// countingParser parses every message as plain text, counting the calls
+type countingParser struct {
+	calls int
+}
+
+func (p *countingParser) Parse(stream, message string) (SlogEvent, bool) {
+	p.calls++
+	return SlogEvent{Message: message}, true
+}
+
+func TestEventCache(t *testing.T) {
+	defer SetParsers(Parsers...)
+	defer SetEventCacheSize(0)
+	if err := SetEventCacheSize(2); err != nil {
+		t.Fatal(err)
+	}
+	parser := &countingParser{}
+	SetParsers(parser.Parse)
+
+	NewEvent(testCWEvent("1", "s", "one", 0), "group")
+	NewEvent(testCWEvent("1", "s", "one", 0), "group")
+	if parser.calls != 1 {
+		t.Errorf("parsed %d times after a cache hit, want 1", parser.calls)
+	}
+
+	// the same ID in another group is a different event
+	NewEvent(testCWEvent("1", "s", "one", 0), "other")
+	if parser.calls != 2 {
+		t.Errorf("parsed %d times after a new group, want 2", parser.calls)
+	}
+
+	// adding a third event evicts the least recently used, group's "1"
+	NewEvent(testCWEvent("2", "s", "two", 0), "group")
+	NewEvent(testCWEvent("1", "s", "one", 0), "other")
+	if parser.calls != 3 {
+		t.Errorf("parsed %d times, want 3", parser.calls)
+	}
+	NewEvent(testCWEvent("1", "s", "one", 0), "group")
+	if parser.calls != 4 {
+		t.Errorf("parsed %d times after an eviction, want 4", parser.calls)
+	}
+
+	// changing the parsers empties the cache
+	SetParsers(parser.Parse)
+	NewEvent(testCWEvent("1", "s", "one", 0), "group")
+	if parser.calls != 5 {
+		t.Errorf("parsed %d times after changing parsers, want 5", parser.calls)
+	}
+}
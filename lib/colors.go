@@ -1,7 +1,6 @@
 package lib
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/fatih/color"
@@ -47,12 +46,13 @@ func Unique(args ...string) string {
 
 // ColorLevel takes a log level and colors it based on severity
 func ColorLevel(l ecslogs.Level) string {
+	name := LocalizeLevel(l)
 	switch l {
 	case ecslogs.ERROR, ecslogs.ALERT, ecslogs.CRIT:
-		return Red(l)
+		return Red(name)
 	case ecslogs.WARN:
-		return Yellow(l)
+		return Yellow(name)
 	default:
-		return fmt.Sprint(l)
+		return name
 	}
 }
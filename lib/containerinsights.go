@@ -0,0 +1,68 @@
+package lib
+
+// ContainerMetrics holds the performance metrics of a container, as reported
+// by CloudWatch Container Insights
+type ContainerMetrics struct {
+	ContainerName string
+	TaskID        string
+	ServiceName   string
+	ClusterName   string
+
+	// CPU is measured in CPU units and memory in MiB
+	CPUUtilized    float64
+	CPUReserved    float64
+	MemoryUtilized float64
+	MemoryReserved float64
+
+	// CPUUtilization and MemoryUtilization are percentages of the reserved
+	// amounts, or zero if no amount was reserved
+	CPUUtilization    float64
+	MemoryUtilization float64
+}
+
+// ContainerMetrics extracts the metrics from a Container Insights performance
+// log event for an ECS container (or an EKS container, which reports its
+// utilization directly).  It returns false if the event isn't a container
+// performance record.
+func (e Event) ContainerMetrics() (ContainerMetrics, bool) {
+	e = e.Parsed()
+	if kind, _ := e.Data["Type"].(string); kind != "Container" {
+		return ContainerMetrics{}, false
+	}
+
+	var m ContainerMetrics
+	m.ContainerName, _ = e.Data["ContainerName"].(string)
+	m.TaskID, _ = e.Data["TaskId"].(string)
+	m.ServiceName, _ = e.Data["ServiceName"].(string)
+	m.ClusterName, _ = e.Data["ClusterName"].(string)
+
+	m.CPUUtilized, _ = dataFloat(e.Data["CpuUtilized"])
+	m.CPUReserved, _ = dataFloat(e.Data["CpuReserved"])
+	m.MemoryUtilized, _ = dataFloat(e.Data["MemoryUtilized"])
+	m.MemoryReserved, _ = dataFloat(e.Data["MemoryReserved"])
+	if m.CPUReserved > 0 {
+		m.CPUUtilization = 100 * m.CPUUtilized / m.CPUReserved
+	}
+	if m.MemoryReserved > 0 {
+		m.MemoryUtilization = 100 * m.MemoryUtilized / m.MemoryReserved
+	}
+
+	// EKS records name the container through kubernetes metadata, and report
+	// utilization as a percentage
+	if m.ContainerName == "" {
+		if name, ok := e.DataValue("kubernetes.container_name"); ok {
+			m.ContainerName, _ = name.(string)
+		}
+		if pod, ok := e.DataValue("kubernetes.pod_name"); ok {
+			m.TaskID, _ = pod.(string)
+		}
+	}
+	if v, ok := dataFloat(e.Data["container_cpu_utilization"]); ok {
+		m.CPUUtilization = v
+	}
+	if v, ok := dataFloat(e.Data["container_memory_utilization"]); ok {
+		m.MemoryUtilization = v
+	}
+
+	return m, true
+}
@@ -0,0 +1,36 @@
+package lib
+
+import "testing"
+
+func TestContainerMetrics(t *testing.T) {
+	ecs := `{"Version":"0","Type":"Container","ContainerName":"web","TaskId":"0123456789abcdef","ServiceName":"api","ClusterName":"prod","CpuUtilized":25.5,"CpuReserved":256.0,"MemoryUtilized":128,"MemoryReserved":512,"Timestamp":1700000000000}`
+	m, ok := NewEvent(testCWEvent("1", "s", ecs, 1), "/aws/ecs/containerinsights/prod/performance").ContainerMetrics()
+	if !ok {
+		t.Fatal("Expected an ECS container record")
+	}
+	want := ContainerMetrics{
+		ContainerName:     "web",
+		TaskID:            "0123456789abcdef",
+		ServiceName:       "api",
+		ClusterName:       "prod",
+		CPUUtilized:       25.5,
+		CPUReserved:       256,
+		MemoryUtilized:    128,
+		MemoryReserved:    512,
+		CPUUtilization:    100 * 25.5 / 256,
+		MemoryUtilization: 25,
+	}
+	if m != want {
+		t.Errorf("Metrics = %+v, want %+v", m, want)
+	}
+
+	eks := `{"Type":"Container","kubernetes":{"container_name":"web","pod_name":"web-6d4f"},"container_cpu_utilization":12.5,"container_memory_utilization":40}`
+	m, ok = NewEvent(testCWEvent("2", "s", eks, 1), "g").ContainerMetrics()
+	if !ok || m.ContainerName != "web" || m.TaskID != "web-6d4f" || m.CPUUtilization != 12.5 || m.MemoryUtilization != 40 {
+		t.Errorf("Unexpected EKS metrics %v %+v", ok, m)
+	}
+
+	if _, ok := NewEvent(testCWEvent("3", "s", `{"Type":"Task","TaskId":"x"}`, 1), "g").ContainerMetrics(); ok {
+		t.Error("Expected a task record not to be a container record")
+	}
+}
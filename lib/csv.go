@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// csvColumns are the columns written by WriteCSV
+var csvColumns = []string{"id", "group", "stream", "level", "time", "creation_time", "ingest_time", "msg", "data"}
+
+// WriteCSV writes events to w as CSV with a header row.  Data fields are
+// written as a JSON object in the data column, and timestamps at
+// OutputTimePrecision.
+func WriteCSV(w io.Writer, events []Event) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		e = e.Parsed()
+		var data []byte
+		if len(e.Data) > 0 {
+			var err error
+			if data, err = json.Marshal(e.Data); err != nil {
+				return err
+			}
+		}
+
+		record := []string{
+			e.ID,
+			e.Group,
+			e.Stream,
+			e.Level.String(),
+			formatCSVTime(e.Time),
+			formatCSVTime(e.CreationTime),
+			formatCSVTime(e.IngestTime),
+			e.Message,
+			string(data),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCSVTime(t time.Time) string {
+	return fmt.Sprint(OutputTimePrecision.formatTime(t, time.RFC3339Nano))
+}
@@ -21,6 +21,8 @@ const (
 var (
 	// MaxStreams is the maximum number of streams you can give to a filter call
 	MaxStreams = 100
+	// Transform, if set, is applied to each fetched event before it is sent
+	Transform func(Event) Event
 )
 
 // CloudwatchLogsReader is responsible for fetching logs for a particular log
@@ -33,6 +35,7 @@ type CloudwatchLogsReader struct {
 	end          time.Time
 	error        error
 	streamPrefix string
+	coalescer    *Coalescer
 }
 
 // Set the maximum number of streams for describe/filter calls
@@ -40,6 +43,12 @@ func SetMaxStreams(max int) {
 	MaxStreams = max
 }
 
+// SetTransform sets a function applied to each fetched event, such as to
+// redact or enrich it.  A nil transform leaves events as they are.
+func SetTransform(transform func(Event) Event) {
+	Transform = transform
+}
+
 // NewCloudwatchLogsReader takes a group and optionally a stream prefix, start and
 // end time, and returns a reader for any logs that match those parameters.
 func NewCloudwatchLogsReader(group string, streamPrefix string, start time.Time, end time.Time) (*CloudwatchLogsReader, error) {
@@ -64,6 +73,10 @@ func NewCloudwatchLogsReader(group string, streamPrefix string, start time.Time,
 		streamPrefix: streamPrefix,
 	}
 
+	if MaxMultilineLines > 0 {
+		reader.coalescer = NewCoalescer(MaxMultilineLines)
+	}
+
 	return reader, nil
 }
 
@@ -122,14 +135,25 @@ func (c *CloudwatchLogsReader) pumpEvents(ctx context.Context, eventChan chan<-
 
 		for _, event := range o.Events {
 			if _, ok := c.eventCache.Peek(*event.EventId); !ok {
-				eventChan <- NewEvent(*event, c.logGroupName)
 				c.eventCache.Add(*event.EventId, nil)
+				if c.coalescer == nil {
+					eventChan <- c.newEvent(*event)
+					continue
+				}
+				for _, e := range c.coalescer.Add(*event) {
+					eventChan <- c.newEvent(e)
+				}
 			}
 		}
 
 		if o.NextToken != nil {
 			params.NextToken = o.NextToken
 		} else if !follow {
+			if c.coalescer != nil {
+				for _, e := range c.coalescer.Flush() {
+					eventChan <- c.newEvent(e)
+				}
+			}
 			close(eventChan)
 			return
 		}
@@ -138,6 +162,19 @@ func (c *CloudwatchLogsReader) pumpEvents(ctx context.Context, eventChan chan<-
 	}
 }
 
+// newEvent builds an Event from a fetched log event, applying fetch options
+func (c *CloudwatchLogsReader) newEvent(cwEvent cloudwatchlogs.FilteredLogEvent) Event {
+	event := NewEvent(cwEvent, c.logGroupName)
+	if StableIndexing {
+		event.Index = StableIndex(event.CreationTime, event.ID)
+	}
+	if Transform != nil {
+		// parse lazy events first, so changes to their fields aren't lost
+		event = Transform(event.Parsed())
+	}
+	return event
+}
+
 // Error returns an error if one occurred while streaming events.
 func (c *CloudwatchLogsReader) Error() error {
 	return c.error
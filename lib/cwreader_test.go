@@ -0,0 +1,26 @@
+package lib
+
+import "testing"
+
+func TestTransform(t *testing.T) {
+	defer SetTransform(nil)
+	c := &CloudwatchLogsReader{logGroupName: "g"}
+
+	SetTransform(func(e Event) Event {
+		e.Message = "[redacted]"
+		return withData(e, "redacted", true)
+	})
+	for _, lazy := range []bool{false, true} {
+		SetLazyParsing(lazy)
+		e := c.newEvent(testCWEvent("1", "s", `{"msg":"password 1234","user":"x"}`, 1)).Parsed()
+		if e.Message != "[redacted]" || e.Data["redacted"] != true || e.Data["user"] != "x" {
+			t.Errorf("lazy %v: Expected the transformed event, got %+v", lazy, e)
+		}
+	}
+	SetLazyParsing(false)
+
+	SetTransform(nil)
+	if e := c.newEvent(testCWEvent("2", "s", "password 1234", 1)); e.Message != "password 1234" {
+		t.Errorf("Expected a nil transform to leave events as they are, got %q", e.Message)
+	}
+}
@@ -0,0 +1,112 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// dataFloat converts a data value to a float64, accepting numbers and
+// strings holding numbers
+func dataFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// dataInt converts a data value to an int64, accepting integral numbers and
+// strings holding integers.  Integers outside the range of an int64 aren't
+// accepted, rather than being truncated.
+func dataInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		// -2^63 is exactly representable, but 2^63 - 1 isn't
+		if n < math.MinInt64 || n >= math.MaxInt64 || n != math.Trunc(n) {
+			return 0, false
+		}
+		return int64(n), true
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// dataBigInt converts a data value to an arbitrary precision integer,
+// accepting integral numbers and strings holding integers
+func dataBigInt(v interface{}) (*big.Int, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return new(big.Int).SetString(n.String(), 10)
+	case string:
+		return new(big.Int).SetString(n, 10)
+	case int:
+		return big.NewInt(int64(n)), true
+	case int64:
+		return big.NewInt(n), true
+	case float64:
+		if math.IsInf(n, 0) || math.IsNaN(n) || n != math.Trunc(n) {
+			return nil, false
+		}
+		i, _ := big.NewFloat(n).Int(nil)
+		return i, true
+	default:
+		return nil, false
+	}
+}
+
+// dataString converts a data value to a string for comparison or grouping.
+// Objects and arrays are encoded as JSON.
+func dataString(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case nil:
+		return "null"
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Sprint(x)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// JSON encodes a data value as JSON, for printing nested objects and arrays
+// in output templates, which would otherwise be printed as Go maps and slices
+func JSON(v interface{}) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
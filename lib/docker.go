@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// dockerLogLine is the envelope the docker json-file log driver wraps each
+// line of container output in
+type dockerLogLine struct {
+	Log    *string `json:"log"`
+	Stream string  `json:"stream"`
+	Time   string  `json:"time"`
+}
+
+// dockerLogKeys are the only keys of a json-file envelope, so that structured
+// logs which happen to have log and stream fields aren't mistaken for one
+var dockerLogKeys = map[string]bool{"log": true, "stream": true, "time": true}
+
+// ParseDocker unwraps messages written by the docker (or containerd) json-file
+// log driver.  The wrapped line is itself run through the parser chain, and
+// the output stream it was written to (stdout or stderr) is placed in Data.
+// Only objects holding nothing but the envelope's log, stream and time are
+// unwrapped.
+func ParseDocker(stream, message string) (SlogEvent, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(message), "{") {
+		return SlogEvent{}, false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(message), &fields); err != nil {
+		return SlogEvent{}, false
+	}
+	for key := range fields {
+		if !dockerLogKeys[key] {
+			return SlogEvent{}, false
+		}
+	}
+
+	var line dockerLogLine
+	if err := json.Unmarshal([]byte(message), &line); err != nil || line.Log == nil {
+		return SlogEvent{}, false
+	}
+	if line.Stream != "stdout" && line.Stream != "stderr" {
+		return SlogEvent{}, false
+	}
+	logged, err := time.Parse(time.RFC3339Nano, line.Time)
+	if err != nil {
+		return SlogEvent{}, false
+	}
+
+	log := strings.TrimRight(*line.Log, "\r\n")
+	event, ok := parseMessage(stream, log)
+	if !ok {
+		event = SlogEvent{
+			Level:   ecslogs.INFO,
+			Message: log,
+		}
+	}
+	if event.Data == nil {
+		event.Data = map[string]interface{}{}
+	}
+	event.Data["stream"] = line.Stream
+
+	if event.Time.IsZero() {
+		event.Time = logged
+	}
+
+	return event, true
+}
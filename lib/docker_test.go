@@ -0,0 +1,59 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func TestParseDocker(t *testing.T) {
+	e, ok := ParseDocker("s", `{"log":"listening on :8080\n","stream":"stdout","time":"2023-11-14T12:00:00.5Z"}`)
+	if !ok {
+		t.Fatal("Failed to parse a stdout line")
+	}
+	if e.Message != "listening on :8080" || e.Level != ecslogs.INFO || e.Data["stream"] != "stdout" {
+		t.Errorf("Unexpected stdout event %+v", e)
+	}
+	if want := time.Date(2023, 11, 14, 12, 0, 0, 5e8, time.UTC); !e.Time.Equal(want) {
+		t.Errorf("Time = %s, want %s", e.Time, want)
+	}
+
+	// wrapped JSON lines are parsed, keeping their own time
+	e, ok = ParseDocker("s", `{"log":"{\"level\":\"ERROR\",\"msg\":\"failed\",\"time\":\"2023-11-14T11:59:59Z\",\"status\":500}\n","stream":"stderr","time":"2023-11-14T12:00:00Z"}`)
+	if !ok {
+		t.Fatal("Failed to parse a stderr line")
+	}
+	if e.Message != "failed" || e.Level != ecslogs.ERROR || e.Data["stream"] != "stderr" || dataString(e.Data["status"]) != "500" {
+		t.Errorf("Unexpected stderr event %+v", e)
+	}
+	if want := time.Date(2023, 11, 14, 11, 59, 59, 0, time.UTC); !e.Time.Equal(want) {
+		t.Errorf("Time = %s, want %s", e.Time, want)
+	}
+
+	for _, message := range []string{
+		`plain text`,
+		`{"msg":"no log field"}`,
+		`{"log":"x","stream":"other","time":"2023-11-14T12:00:00Z"}`,
+		`{"log":"x","stream":"stdout"}`,
+		`{"log":"x","stream":"stdout","time":"yesterday"}`,
+	} {
+		if _, ok := ParseDocker("s", message); ok {
+			t.Errorf("Parsed %q as a docker line", message)
+		}
+	}
+}
+
+func TestParseDockerStructuredLog(t *testing.T) {
+	// an slog line which happens to have log and stream fields isn't an
+	// envelope, so it's left for ParseJSON
+	message := `{"level":"ERROR","msg":"disk full","log":"x","stream":"stdout","time":"2023-11-14T12:00:00Z"}`
+	if _, ok := ParseDocker("s", message); ok {
+		t.Errorf("Parsed %q as a docker line", message)
+	}
+
+	e := NewEvent(testCWEvent("1", "s", message, 1), "g")
+	if e.Message != "disk full" || e.Level != ecslogs.ERROR || e.Data["log"] != "x" || e.Data["stream"] != "stdout" {
+		t.Errorf("Expected the line to be parsed as JSON, got %+v", e)
+	}
+}
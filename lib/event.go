@@ -1,9 +1,14 @@
 package lib
 
 import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,14 +33,25 @@ type Event struct {
 	ID           string
 	IngestTime   time.Time
 	CreationTime time.Time
+	// Seq is the event's position within its batch, set by AssignSequence
+	Seq int
+	// Index orders the event among all fetched events, set by StableIndex
+	// when StableIndexing is enabled
+	Index int64
+
+	structured bool
+	lazy       *lazyMessage
 }
 
 type SlogEvent struct {
-	Level   ecslogs.Level     `json:"level"`
-	Time    time.Time         `json:"time"`
-	Source  SourceInfo        `json:"source"`
-	Message string            `json:"msg"`
-	Data    map[string]string `json:"-"`
+	Level   ecslogs.Level          `json:"level"`
+	Time    time.Time              `json:"time"`
+	Source  SourceInfo             `json:"source"`
+	Message string                 `json:"msg"`
+	Data    map[string]interface{} `json:"-"`
+
+	// stream is set by parsers to override the event's stream
+	stream string
 }
 
 type SourceInfo struct {
@@ -74,7 +90,7 @@ func (s *SlogEvent) UnmarshalJSON(data []byte) error {
 		}
 	}
 
-	s.Data = make(map[string]string)
+	s.Data = make(map[string]interface{})
 	staticFields := map[string]bool{
 		"level":  true,
 		"time":   true,
@@ -84,47 +100,79 @@ func (s *SlogEvent) UnmarshalJSON(data []byte) error {
 
 	for key, value := range raw {
 		if !staticFields[key] {
-			var str string
-			if err := json.Unmarshal(value, &str); err != nil {
-				var num json.Number
-				if err := json.Unmarshal(value, &num); err != nil {
-					s.Data[key] = string(value)
-				} else {
-					s.Data[key] = string(num)
-				}
-			} else {
-				s.Data[key] = str
+			v, err := decodeValue(value)
+			if err != nil {
+				return err
 			}
+			s.Data[key] = v
 		}
 	}
 
 	return nil
 }
 
+// decodeValue decodes a JSON value for use in Data, keeping numbers as
+// json.Number so that they aren't rounded
+func decodeValue(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
 // NewEvent takes a cloudwatch log event and returns an Event
 func NewEvent(cwEvent cloudwatchlogs.FilteredLogEvent, group string) Event {
-	var ecsLogsEvent SlogEvent
-	if err := json.Unmarshal([]byte(*cwEvent.Message), &ecsLogsEvent); err != nil {
+	if entry, ok := lookupParsedEvent(group, *cwEvent.EventId); ok {
+		return entry.event
+	}
+
+	event := newEvent(cwEvent, group)
+	cacheParsedEvent(event)
+	return event
+}
+
+func newEvent(cwEvent cloudwatchlogs.FilteredLogEvent, group string) Event {
+	event := Event{
+		Stream:       *cwEvent.LogStreamName,
+		Group:        group,
+		ID:           *cwEvent.EventId,
+		IngestTime:   ParseAWSTimestamp(cwEvent.IngestionTime),
+		CreationTime: ParseAWSTimestamp(cwEvent.Timestamp),
+	}
+
+	if LazyParsing {
+		event.lazy = &lazyMessage{raw: *cwEvent.Message, base: event}
+		return event
+	}
+	return event.parse(*cwEvent.Message)
+}
+
+// parse fills in the parts of the event that come from its raw message
+func (e Event) parse(message string) Event {
+	ecsLogsEvent, ok := parseWith(ParsersForGroup(e.Group), e.Stream, message)
+	if !ok {
 		ecsLogsEvent = SlogEvent{
 			Level:   ecslogs.INFO,
-			Message: *cwEvent.Message,
+			Message: message,
 		}
 	}
 
 	// If time was not found use AWS Timestamp
 	if ecsLogsEvent.Time.IsZero() {
-		ecsLogsEvent.Time = ParseAWSTimestamp(cwEvent.Timestamp)
+		ecsLogsEvent.Time = e.CreationTime
 	}
 
-	return Event{
-		SlogEvent:        ecsLogsEvent,
-		Stream:       *cwEvent.LogStreamName,
-		Group:        group,
-		ID:           *cwEvent.EventId,
-		IngestTime:   ParseAWSTimestamp(cwEvent.IngestionTime),
-		CreationTime: ParseAWSTimestamp(cwEvent.Timestamp),
+	if ecsLogsEvent.stream != "" {
+		e.Stream = ecsLogsEvent.stream
+		ecsLogsEvent.stream = ""
 	}
 
+	e.SlogEvent = ecsLogsEvent
+	e.structured = ok
+	return e
 }
 
 // ParseAWSTimestamp takes the time stamp format given by AWS and returns an equivalent time.Time value
@@ -135,9 +183,22 @@ func ParseAWSTimestamp(i *int64) time.Time {
 	return time.Unix(*i/1e3, (*i%1e3)*1e6)
 }
 
+// IsStructured reports whether the event's message was in a format one of the
+// parsers understood, rather than plain text
+func (e Event) IsStructured() bool {
+	return e.Parsed().structured
+}
+
+// IngestLag is how long after the event was created that CloudWatch ingested
+// it
+func (e Event) IngestLag() time.Duration {
+	return e.IngestTime.Sub(e.CreationTime)
+}
+
 // TaskShort attempts to shorten a stream name if it is a task UUID, leaving the stream
 // name intact if it is not a UUID
 func (e Event) TaskShort() string {
+	e = e.Parsed()
 	if TaskUUIDPattern.MatchString(e.Stream) {
 		uuidParts := strings.Split(e.Stream, "-")
 		return uuidParts[0]
@@ -147,14 +208,96 @@ func (e Event) TaskShort() string {
 
 // TimeShort gives the timestamp of an event in a readable format
 func (e Event) TimeShort() string {
-	return e.Time.Local().Format(ShortTimeFormat)
+	return e.Parsed().Time.Local().Format(ShortTimeFormat)
 }
 
+// DataFlat returns a copy of Data with nested keys flattened.  The result is
+// memoized for events held in the event cache.
 func (e Event) DataFlat() map[string]interface{} {
+	e = e.Parsed()
+	if entry, ok := lookupParsedEvent(e.Group, e.ID); ok && entry.holds(e) {
+		entry.flatOnce.Do(func() {
+			entry.flat = bellows.Flatten(entry.event.Data)
+		})
+		return entry.flat
+	}
 	return bellows.Flatten(e.Data)
 }
 
+// DataValue returns the value of a data field.  Fields nested within objects
+// can be looked up with a dotted key (e.g. "request.method").
+func (e Event) DataValue(key string) (interface{}, bool) {
+	e = e.Parsed()
+	if v, ok := e.Data[key]; ok {
+		return v, true
+	}
+
+	var value interface{} = e.Data
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// DataInt returns the value of a data field as an int64.  It returns false if
+// the field isn't an integer, or is too large for an int64, in which case
+// DataBigInt can be used instead.
+func (e Event) DataInt(key string) (int64, bool) {
+	v, ok := e.DataValue(key)
+	if !ok {
+		return 0, false
+	}
+	return dataInt(v)
+}
+
+// DataBigInt returns the value of a data field as an integer of any size, such
+// as a 20 digit ID
+func (e Event) DataBigInt(key string) (*big.Int, bool) {
+	v, ok := e.DataValue(key)
+	if !ok {
+		return nil, false
+	}
+	return dataBigInt(v)
+}
+
+// Duration returns the value of a data field as a duration.  Strings are
+// parsed with time.ParseDuration (e.g. "12ms"), and numbers, including strings
+// holding only a number, are taken to be milliseconds.
+func (e Event) Duration(key string) (time.Duration, bool) {
+	v, ok := e.DataValue(key)
+	if !ok {
+		return 0, false
+	}
+	if ms, ok := dataFloat(v); ok {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	if s, ok := v.(string); ok {
+		d, err := time.ParseDuration(s)
+		return d, err == nil
+	}
+	return 0, false
+}
+
+// Fingerprint identifies the content of an event, ignoring which stream it
+// was written to and when.  Events with the same level, message and data have
+// the same fingerprint.
+func (e Event) Fingerprint() string {
+	e = e.Parsed()
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", e.Level, e.Message)
+	// maps are encoded with sorted keys, so this is stable
+	json.NewEncoder(h).Encode(e.Data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func (e Event) PrettyPrint() string {
+	e = e.Parsed()
 	pretty, err := json.MarshalIndent(e, "", "  ")
 	if err != nil {
 		return fmt.Sprintf("%+v", e)
@@ -168,3 +311,71 @@ type ByCreationTime []Event
 func (b ByCreationTime) Len() int           { return len(b) }
 func (b ByCreationTime) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
 func (b ByCreationTime) Less(i, j int) bool { return b[i].CreationTime.Before(b[j].CreationTime) }
+
+// AssignSequence returns a copy of events sorted by creation time, with each
+// event's Seq set to its position in that order, starting at zero
+func AssignSequence(events []Event) []Event {
+	sequenced := make([]Event, len(events))
+	copy(sequenced, events)
+	sort.Stable(ByCreationTime(sequenced))
+	for ix := range sequenced {
+		sequenced[ix].Seq = ix
+	}
+	return sequenced
+}
+
+// MergeEvents returns a copy of base with any non-zero fields of overlay
+// applied to it.  Data maps are merged, with overlay's values winning for keys
+// present in both.  Neither input is modified.
+func MergeEvents(base, overlay Event) Event {
+	base, overlay = base.Parsed(), overlay.Parsed()
+	merged := base
+	var noLevel ecslogs.Level
+
+	if overlay.Level != noLevel {
+		merged.Level = overlay.Level
+	}
+	if !overlay.Time.IsZero() {
+		merged.Time = overlay.Time
+	}
+	if overlay.Source != (SourceInfo{}) {
+		merged.Source = overlay.Source
+	}
+	if overlay.Message != "" {
+		merged.Message = overlay.Message
+	}
+	if overlay.Stream != "" {
+		merged.Stream = overlay.Stream
+	}
+	if overlay.Group != "" {
+		merged.Group = overlay.Group
+	}
+	if overlay.ID != "" {
+		merged.ID = overlay.ID
+	}
+	if !overlay.IngestTime.IsZero() {
+		merged.IngestTime = overlay.IngestTime
+	}
+	if !overlay.CreationTime.IsZero() {
+		merged.CreationTime = overlay.CreationTime
+	}
+	if overlay.Seq != 0 {
+		merged.Seq = overlay.Seq
+	}
+	if overlay.Index != 0 {
+		merged.Index = overlay.Index
+	}
+	merged.structured = base.structured || overlay.structured
+
+	if base.Data != nil || overlay.Data != nil {
+		merged.Data = make(map[string]interface{}, len(base.Data)+len(overlay.Data))
+		for k, v := range base.Data {
+			merged.Data[k] = v
+		}
+		for k, v := range overlay.Data {
+			merged.Data[k] = v
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,108 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+// testCWEvent builds a CloudWatch log event created at ms milliseconds since
+// the epoch
+func testCWEvent(id, stream, message string, ms int64) cloudwatchlogs.FilteredLogEvent {
+	return cloudwatchlogs.FilteredLogEvent{
+		EventId:       aws.String(id),
+		LogStreamName: aws.String(stream),
+		Message:       aws.String(message),
+		Timestamp:     aws.Int64(ms),
+		IngestionTime: aws.Int64(ms),
+	}
+}
+
+func TestAssignSequence(t *testing.T) {
+	base := time.Unix(60000, 0)
+	events := []Event{
+		{ID: "c", CreationTime: base.Add(2 * time.Second)},
+		{ID: "a", CreationTime: base},
+		{ID: "b1", CreationTime: base.Add(time.Second)},
+		{ID: "b2", CreationTime: base.Add(time.Second)},
+	}
+
+	sequenced := AssignSequence(events)
+	// events created at the same time keep their order
+	for ix, id := range []string{"a", "b1", "b2", "c"} {
+		if sequenced[ix].ID != id || sequenced[ix].Seq != ix {
+			t.Errorf("Event %d = %s with Seq %d, want %s with Seq %d", ix, sequenced[ix].ID, sequenced[ix].Seq, id, ix)
+		}
+	}
+	if events[0].ID != "c" || events[0].Seq != 0 {
+		t.Error("Expected the original events to be left untouched")
+	}
+}
+
+func TestMergeEvents(t *testing.T) {
+	base := Event{
+		ID:     "1",
+		Stream: "s",
+		SlogEvent: SlogEvent{
+			Message: "original",
+			Data:    map[string]interface{}{"status": 200, "path": "/"},
+		},
+	}
+	overlay := Event{
+		Stream: "t",
+		SlogEvent: SlogEvent{
+			Data: map[string]interface{}{"status": 500, "region": "us-east-1"},
+		},
+	}
+
+	merged := MergeEvents(base, overlay)
+	if merged.ID != "1" || merged.Message != "original" {
+		t.Errorf("Expected zero overlay fields to keep the base values, got %+v", merged)
+	}
+	if merged.Stream != "t" {
+		t.Errorf("Stream = %s, want the overlay's t", merged.Stream)
+	}
+	want := map[string]interface{}{"status": 500, "path": "/", "region": "us-east-1"}
+	if len(merged.Data) != len(want) {
+		t.Errorf("Data = %v, want %v", merged.Data, want)
+	}
+	for key, value := range want {
+		if merged.Data[key] != value {
+			t.Errorf("Data[%s] = %v, want %v", key, merged.Data[key], value)
+		}
+	}
+
+	if base.Data["status"] != 200 || len(base.Data) != 2 || base.Stream != "s" {
+		t.Errorf("Expected the base to be left untouched, got %+v", base)
+	}
+	if overlay.Data["status"] != 500 || len(overlay.Data) != 2 {
+		t.Errorf("Expected the overlay to be left untouched, got %+v", overlay)
+	}
+}
+
+func TestDataBigInt(t *testing.T) {
+	e := NewEvent(testCWEvent("1", "s", `{"msg":"m","id":1234567890123456789012345,"quoted":"1234567890123456789012345","small":42,"fraction":1.5}`, 1), "g")
+
+	for _, key := range []string{"id", "quoted"} {
+		if _, ok := e.DataInt(key); ok {
+			t.Errorf("Expected DataInt(%s) to reject a 25 digit integer", key)
+		}
+		if n, ok := e.DataBigInt(key); !ok || n.String() != "1234567890123456789012345" {
+			t.Errorf("DataBigInt(%s) = %v, %v", key, n, ok)
+		}
+	}
+	if n, ok := e.DataInt("small"); !ok || n != 42 {
+		t.Errorf("DataInt(small) = %d, %v, want 42", n, ok)
+	}
+	if n, ok := e.DataBigInt("small"); !ok || n.Int64() != 42 {
+		t.Errorf("DataBigInt(small) = %v, %v, want 42", n, ok)
+	}
+	if _, ok := e.DataInt("fraction"); ok {
+		t.Error("Expected DataInt to reject a fraction")
+	}
+	if _, ok := e.DataBigInt("fraction"); ok {
+		t.Error("Expected DataBigInt to reject a fraction")
+	}
+}
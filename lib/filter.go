@@ -0,0 +1,103 @@
+package lib
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+)
+
+// Sample returns approximately rate (between 0 and 1) of events, chosen by
+// hashing each event's ID with seed.  The same events, rate and seed always
+// give the same selection.
+func Sample(events []Event, rate float64, seed int64) []Event {
+	var seedBytes [8]byte
+	binary.LittleEndian.PutUint64(seedBytes[:], uint64(seed))
+
+	sampled := []Event{}
+	for _, e := range events {
+		h := fnv.New64a()
+		h.Write(seedBytes[:])
+		h.Write([]byte(e.ID))
+		if float64(mix64(h.Sum64())) < rate*math.MaxUint64 {
+			sampled = append(sampled, e)
+		}
+	}
+	return sampled
+}
+
+// mix64 spreads the bits of an FNV hash, which otherwise cluster for short
+// inputs that differ only in their last bytes
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// FilterByFieldPresence returns the events which have the data field key if
+// present is true, or which are missing it if present is false.  Nested fields
+// can be given as a dotted key.
+func FilterByFieldPresence(events []Event, key string, present bool) []Event {
+	filtered := []Event{}
+	for _, e := range events {
+		if _, ok := e.DataValue(key); ok == present {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// FilterByFields returns the events whose data matches every key=value pair
+// in conditions.  Values are compared as strings, so conditions{"status":
+// "500"} matches a numeric status of 500, and nested fields can be given as a
+// dotted key.
+func FilterByFields(events []Event, conditions map[string]string) []Event {
+	filtered := []Event{}
+	for _, e := range events {
+		if matchesFields(e, conditions) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+func matchesFields(e Event, conditions map[string]string) bool {
+	for key, want := range conditions {
+		v, ok := e.DataValue(key)
+		if !ok || dataString(v) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// CompilePipeline combines predicates into a single predicate which matches
+// events matching all of them.  Stages are checked in order, stopping at the
+// first that doesn't match, so cheaper or more selective stages should come
+// first.
+func CompilePipeline(stages ...func(Event) bool) func(Event) bool {
+	pipeline := make([]func(Event) bool, len(stages))
+	copy(pipeline, stages)
+
+	return func(e Event) bool {
+		for _, stage := range pipeline {
+			if !stage(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// FilterStream sends the events read from in which match pred to out, closing
+// out once in is closed
+func FilterStream(in <-chan Event, out chan<- Event, pred func(Event) bool) {
+	defer close(out)
+	for e := range in {
+		if pred(e) {
+			out <- e
+		}
+	}
+}
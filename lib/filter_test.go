@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	events := make([]Event, 10000)
+	for ix := range events {
+		events[ix] = Event{ID: fmt.Sprint(ix)}
+	}
+
+	sampled := Sample(events, 0.25, 7)
+	if len(sampled) < 2300 || len(sampled) > 2700 {
+		t.Errorf("Expected about 2500 events at a rate of 0.25, got %d", len(sampled))
+	}
+	again := Sample(events, 0.25, 7)
+	if len(again) != len(sampled) {
+		t.Fatalf("Expected the same sample for the same seed, got %d and %d events", len(sampled), len(again))
+	}
+	for ix := range sampled {
+		if sampled[ix].ID != again[ix].ID {
+			t.Fatalf("Expected the same sample for the same seed, got %s and %s at %d", sampled[ix].ID, again[ix].ID, ix)
+		}
+	}
+
+	if other := Sample(events, 0.25, 8); fmt.Sprint(other[:10]) == fmt.Sprint(sampled[:10]) {
+		t.Error("Expected a different sample for a different seed")
+	}
+	if n := len(Sample(events, 1, 7)); n != len(events) {
+		t.Errorf("Expected every event at a rate of 1, got %d", n)
+	}
+	if n := len(Sample(events, 0, 7)); n != 0 {
+		t.Errorf("Expected no events at a rate of 0, got %d", n)
+	}
+}
+
+// filterIDs returns the IDs of events, for comparing filtered results
+func filterIDs(events []Event) string {
+	ids := make([]string, len(events))
+	for ix, e := range events {
+		ids[ix] = e.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestFilterByFieldPresence(t *testing.T) {
+	events := []Event{
+		NewEvent(testCWEvent("1", "s", `{"msg":"a","user":"x"}`, 1), "g"),
+		NewEvent(testCWEvent("2", "s", `{"msg":"b","request":{"user":"y"}}`, 1), "g"),
+		NewEvent(testCWEvent("3", "s", `plain`, 1), "g"),
+	}
+
+	if got := filterIDs(FilterByFieldPresence(events, "user", true)); got != "[1]" {
+		t.Errorf("Events with user = %s, want [1]", got)
+	}
+	if got := filterIDs(FilterByFieldPresence(events, "request.user", true)); got != "[2]" {
+		t.Errorf("Events with request.user = %s, want [2]", got)
+	}
+	if got := filterIDs(FilterByFieldPresence(events, "user", false)); got != "[2 3]" {
+		t.Errorf("Events without user = %s, want [2 3]", got)
+	}
+}
+
+func TestCompilePipeline(t *testing.T) {
+	var calls []string
+	stage := func(name string, match bool) func(Event) bool {
+		return func(Event) bool {
+			calls = append(calls, name)
+			return match
+		}
+	}
+
+	if !CompilePipeline(stage("a", true), stage("b", true))(Event{}) {
+		t.Error("Expected a match when every stage matches")
+	}
+	calls = nil
+	if CompilePipeline(stage("a", true), stage("b", false), stage("c", true))(Event{}) {
+		t.Error("Expected no match when a stage doesn't match")
+	}
+	if fmt.Sprint(calls) != "[a b]" {
+		t.Errorf("Expected the stages after the first mismatch to be skipped, called %v", calls)
+	}
+	if !CompilePipeline()(Event{}) {
+		t.Error("Expected an empty pipeline to match everything")
+	}
+}
+
+func TestFilterStream(t *testing.T) {
+	in := make(chan Event)
+	out := make(chan Event)
+	go FilterStream(in, out, func(e Event) bool { return e.Stream == "keep" })
+	go func() {
+		for ix, stream := range []string{"keep", "drop", "keep", "drop"} {
+			in <- Event{ID: fmt.Sprint(ix), Stream: stream}
+		}
+		close(in)
+	}()
+
+	var filtered []Event
+	for e := range out {
+		filtered = append(filtered, e)
+	}
+	if got := filterIDs(filtered); got != "[0 2]" {
+		t.Errorf("Filtered events = %s, want [0 2]", got)
+	}
+}
+
+func TestFilterByFields(t *testing.T) {
+	events := []Event{
+		NewEvent(testCWEvent("1", "s", `{"msg":"a","status":500,"request":{"method":"GET"}}`, 1), "g"),
+		NewEvent(testCWEvent("2", "s", `{"msg":"b","status":"500","request":{"method":"POST"}}`, 1), "g"),
+		NewEvent(testCWEvent("3", "s", `{"msg":"c","status":200,"request":{"method":"GET"}}`, 1), "g"),
+		NewEvent(testCWEvent("4", "s", `plain`, 1), "g"),
+	}
+
+	tests := []struct {
+		conditions map[string]string
+		want       string
+	}{
+		{map[string]string{"status": "500"}, "[1 2]"},
+		{map[string]string{"status": "500", "request.method": "GET"}, "[1]"},
+		{map[string]string{"request.method": "DELETE"}, "[]"},
+		{map[string]string{}, "[1 2 3 4]"},
+	}
+	for _, test := range tests {
+		if got := filterIDs(FilterByFields(events, test.conditions)); got != test.want {
+			t.Errorf("FilterByFields(%v) = %s, want %s", test.conditions, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package lib
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// ParseFluentBit parses records forwarded by Fluent Bit, either as a
+// [timestamp, {record}] tuple or as a record object holding the original line
+// under "log".  The original line is run through the parser chain, and the
+// remaining record fields are placed in Data.  A record's level, time and
+// source fields are used for lines which don't have their own.
+func ParseFluentBit(stream, message string) (SlogEvent, bool) {
+	trimmed := strings.TrimSpace(message)
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		return parseFluentBitTuple(stream, []byte(trimmed))
+	case strings.HasPrefix(trimmed, "{"):
+		return parseFluentBitRecord(stream, []byte(trimmed))
+	default:
+		return SlogEvent{}, false
+	}
+}
+
+func parseFluentBitTuple(stream string, data []byte) (SlogEvent, bool) {
+	var tuple []json.RawMessage
+	if err := json.Unmarshal(data, &tuple); err != nil || len(tuple) != 2 {
+		return SlogEvent{}, false
+	}
+
+	ts, ok := parseFluentBitTime(tuple[0])
+	if !ok {
+		return SlogEvent{}, false
+	}
+
+	event, ok := parseFluentBitRecord(stream, tuple[1])
+	if !ok {
+		if event, ok = ParseJSON(stream, string(tuple[1])); !ok {
+			return SlogEvent{}, false
+		}
+	}
+	event.Time = ts
+
+	return event, true
+}
+
+func parseFluentBitRecord(stream string, data []byte) (SlogEvent, bool) {
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(data, &record); err != nil {
+		return SlogEvent{}, false
+	}
+	if _, ok := record["msg"]; ok {
+		return SlogEvent{}, false
+	}
+
+	var log string
+	if raw, ok := record["log"]; !ok || json.Unmarshal(raw, &log) != nil {
+		return SlogEvent{}, false
+	}
+	log = strings.TrimRight(log, "\r\n")
+
+	// The record's own level, time and source apply unless the line has them
+	var top SlogEvent
+	if err := json.Unmarshal(data, &top); err != nil {
+		return SlogEvent{}, false
+	}
+
+	event, ok := parseMessage(stream, log)
+	if !ok {
+		event = SlogEvent{Message: log}
+	}
+	var noLevel ecslogs.Level
+	if event.Level == noLevel {
+		event.Level = top.Level
+	}
+	if event.Level == noLevel && !ok {
+		event.Level = ecslogs.INFO
+	}
+	if event.Time.IsZero() {
+		event.Time = top.Time
+	}
+	if event.Source == (SourceInfo{}) {
+		event.Source = top.Source
+	}
+	if event.Data == nil {
+		event.Data = map[string]interface{}{}
+	}
+
+	for key, value := range top.Data {
+		if _, ok := event.Data[key]; ok || key == "log" {
+			continue
+		}
+		event.Data[key] = value
+	}
+
+	if event.Time.IsZero() {
+		if ts, ok := parseFluentBitTime(record["date"]); ok {
+			event.Time = ts
+		}
+	}
+
+	return event, true
+}
+
+// parseFluentBitTime parses a Fluent Bit timestamp, which is either a number
+// of seconds since the epoch or an [seconds, {metadata}] pair
+func parseFluentBitTime(data json.RawMessage) (time.Time, bool) {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err == nil && len(pair) > 0 {
+		data = pair[0]
+	}
+
+	var secs float64
+	if err := json.Unmarshal(data, &secs); err != nil {
+		return time.Time{}, false
+	}
+	whole, frac := math.Modf(secs)
+	return time.Unix(int64(whole), int64(frac*1e9)).UTC(), true
+}
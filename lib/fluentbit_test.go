@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func TestParseFluentBit(t *testing.T) {
+	e, ok := ParseFluentBit("s", `[1700000000.5, {"log":"{\"msg\":\"failed\",\"level\":\"ERROR\"}","container":"web"}]`)
+	if !ok {
+		t.Fatal("Failed to parse a tuple")
+	}
+	if e.Message != "failed" || e.Level != ecslogs.ERROR || e.Data["container"] != "web" {
+		t.Errorf("Unexpected tuple event %+v", e)
+	}
+	if want := time.Unix(1700000000, 5e8); !e.Time.Equal(want) {
+		t.Errorf("Time = %s, want %s", e.Time, want)
+	}
+
+	e, ok = ParseFluentBit("s", `{"date":1700000000,"log":"plain line\n","container":"worker"}`)
+	if !ok {
+		t.Fatal("Failed to parse a record")
+	}
+	if e.Message != "plain line" || e.Data["container"] != "worker" {
+		t.Errorf("Unexpected record event %+v", e)
+	}
+	if want := time.Unix(1700000000, 0); !e.Time.Equal(want) {
+		t.Errorf("Time = %s, want %s", e.Time, want)
+	}
+
+	for _, message := range []string{
+		`plain text`,
+		`{"msg":"no log field"}`,
+		`[1700000000]`,
+		`["not a time", {"log":"x"}]`,
+	} {
+		if _, ok := ParseFluentBit("s", message); ok {
+			t.Errorf("Parsed %q as a Fluent Bit record", message)
+		}
+	}
+}
+
+func TestParseFluentBitRecordLevel(t *testing.T) {
+	e := NewEvent(testCWEvent("1", "s", `{"level":"ERROR","time":"2023-11-14T12:00:00Z","log":"disk full"}`, 1), "g")
+	if e.Message != "disk full" || e.Level != ecslogs.ERROR {
+		t.Errorf("Expected 'disk full' at %s, got %q at %s", ecslogs.ERROR, e.Message, e.Level)
+	}
+	if want := time.Date(2023, 11, 14, 12, 0, 0, 0, time.UTC); !e.Time.Equal(want) {
+		t.Errorf("Time = %s, want %s", e.Time, want)
+	}
+	for _, key := range []string{"level", "time", "log"} {
+		if _, ok := e.Data[key]; ok {
+			t.Errorf("Expected %s not to be in Data, got %v", key, e.Data)
+		}
+	}
+
+	// the line's own level wins over the record's
+	e = NewEvent(testCWEvent("2", "s", `{"level":"INFO","log":"{\"level\":\"WARN\",\"msg\":\"slow\"}"}`, 1), "g")
+	if e.Message != "slow" || e.Level != ecslogs.WARN {
+		t.Errorf("Expected 'slow' at %s, got %q at %s", ecslogs.WARN, e.Message, e.Level)
+	}
+}
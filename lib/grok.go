@@ -0,0 +1,98 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// grokAliases are the built in patterns which can be referenced from a grok
+// pattern as %{NAME} or %{NAME:field}
+var grokAliases = map[string]string{
+	"IP":                `(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)(?:\.(?:25[0-5]|2[0-4]\d|1?\d?\d)){3}|[0-9A-Fa-f]*:[0-9A-Fa-f:.]+)`,
+	"NUMBER":            `[+-]?(?:\d+(?:\.\d*)?|\.\d+)`,
+	"TIMESTAMP_ISO8601": `\d{4}-\d{2}-\d{2}[T ]\d{2}:?\d{2}(?::?\d{2}(?:[.,]\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?`,
+	"WORD":              `\w+`,
+	"NOTSPACE":          `\S+`,
+	"DATA":              `.*?`,
+	"GREEDYDATA":        `.*`,
+}
+
+var grokReference = regexp.MustCompile(`%\{(\w+)(?::([\w.@-]+))?\}`)
+
+// GrokParser extracts fields from unstructured messages using a grok pattern
+type GrokParser struct {
+	pattern  *regexp.Regexp
+	captures map[int]string
+}
+
+// NewGrokParser compiles a grok pattern, which is a regular expression that may
+// also reference the built in patterns (IP, NUMBER, TIMESTAMP_ISO8601, WORD,
+// NOTSPACE, DATA and GREEDYDATA).  References of the form %{NAME:field} are
+// captured into Data under the given field name, as are any named groups in
+// the regular expression itself.
+func NewGrokParser(pattern string) (*GrokParser, error) {
+	var fields []string
+	var unknown []string
+	expanded := grokReference.ReplaceAllStringFunc(pattern, func(ref string) string {
+		match := grokReference.FindStringSubmatch(ref)
+		alias, ok := grokAliases[match[1]]
+		if !ok {
+			unknown = append(unknown, match[1])
+			return ref
+		}
+		if match[2] == "" {
+			return "(?:" + alias + ")"
+		}
+		fields = append(fields, match[2])
+		return fmt.Sprintf("(?P<grok%d>%s)", len(fields)-1, alias)
+	})
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("Unknown grok patterns: %s", strings.Join(unknown, ", "))
+	}
+
+	re, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map each capture group to its field name.  Named groups written as
+	// plain regular expressions are captured too.
+	captures := map[int]string{}
+	for ix, name := range re.SubexpNames() {
+		var field int
+		if _, err := fmt.Sscanf(name, "grok%d", &field); err == nil && field < len(fields) {
+			captures[ix] = fields[field]
+		} else if name != "" {
+			captures[ix] = name
+		}
+	}
+
+	return &GrokParser{
+		pattern:  re,
+		captures: captures,
+	}, nil
+}
+
+// Parse matches the message against the grok pattern, placing any captured
+// fields in Data.  It is meant to be added to the end of the parser chain so
+// that it only applies to messages in no other format.
+func (g *GrokParser) Parse(stream, message string) (SlogEvent, bool) {
+	match := g.pattern.FindStringSubmatch(message)
+	if match == nil {
+		return SlogEvent{}, false
+	}
+
+	event := SlogEvent{
+		Level:   ecslogs.INFO,
+		Message: message,
+		Data:    make(map[string]interface{}, len(g.captures)),
+	}
+	for ix, field := range g.captures {
+		event.Data[field] = match[ix]
+	}
+
+	return event, true
+}
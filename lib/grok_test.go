@@ -0,0 +1,29 @@
+package lib
+
+import "testing"
+
+func TestGrokParser(t *testing.T) {
+	g, err := NewGrokParser(`^%{IP:client.ip} took %{NUMBER:duration_ms}ms at %{TIMESTAMP_ISO8601}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := "10.0.0.1 took 12.5ms at 2023-11-14T12:00:00Z"
+	e, ok := g.Parse("s", message)
+	if !ok {
+		t.Fatal("Failed to match the pattern")
+	}
+	if e.Message != message {
+		t.Errorf("Message = %q, want the whole message", e.Message)
+	}
+	if len(e.Data) != 2 || e.Data["client.ip"] != "10.0.0.1" || e.Data["duration_ms"] != "12.5" {
+		t.Errorf("Data = %v, want client.ip and duration_ms", e.Data)
+	}
+
+	if _, ok := g.Parse("s", "took 12.5ms"); ok {
+		t.Error("Matched a message without a client IP")
+	}
+	if _, err := NewGrokParser("%{UNKNOWN:field}"); err == nil {
+		t.Error("Expected an error for an unknown pattern")
+	}
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"time"
+)
+
+const (
+	// DayFormat is the format of the keys returned by GroupByDay
+	DayFormat = "2006-01-02"
+	// MissingValue is the key used for events without the field being grouped by
+	MissingValue = "(missing)"
+)
+
+// GroupByDay groups events by the calendar day they were created on in loc,
+// keyed as YYYY-MM-DD.  A nil location is treated as UTC.
+func GroupByDay(events []Event, loc *time.Location) map[string][]Event {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	days := map[string][]Event{}
+	for _, e := range events {
+		day := e.CreationTime.In(loc).Format(DayFormat)
+		days[day] = append(days[day], e)
+	}
+	return days
+}
+
+// CountByField counts events by the value of the (possibly dotted) data field
+// key, converted to a string.  Events without the field are counted under
+// MissingValue.
+func CountByField(events []Event, key string) map[string]int {
+	counts := map[string]int{}
+	for _, e := range events {
+		value := MissingValue
+		if v, ok := e.DataValue(key); ok {
+			value = dataString(v)
+		}
+		counts[value]++
+	}
+	return counts
+}
+
+// CoOccurrence counts how often each value of the data field keyA appears
+// alongside each value of keyB in the same event, keyed by the value of keyA
+// and then keyB.  Events without one of the fields count it as MissingValue.
+func CoOccurrence(events []Event, keyA, keyB string) map[string]map[string]int {
+	counts := map[string]map[string]int{}
+	for _, e := range events {
+		a, b := MissingValue, MissingValue
+		if v, ok := e.DataValue(keyA); ok {
+			a = dataString(v)
+		}
+		if v, ok := e.DataValue(keyB); ok {
+			b = dataString(v)
+		}
+
+		if counts[a] == nil {
+			counts[a] = map[string]int{}
+		}
+		counts[a][b]++
+	}
+	return counts
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGroupByDay(t *testing.T) {
+	// 03:30 UTC on the 15th is still the 14th in New York
+	events := []Event{
+		{ID: "1", CreationTime: time.Date(2023, 11, 14, 23, 30, 0, 0, time.UTC)},
+		{ID: "2", CreationTime: time.Date(2023, 11, 15, 3, 30, 0, 0, time.UTC)},
+		{ID: "3", CreationTime: time.Date(2023, 11, 15, 6, 0, 0, 0, time.UTC)},
+	}
+
+	newYork := time.FixedZone("EST", -5*60*60)
+	days := GroupByDay(events, newYork)
+	if len(days) != 2 || filterIDs(days["2023-11-14"]) != "[1 2]" || filterIDs(days["2023-11-15"]) != "[3]" {
+		t.Errorf("Days in New York = %v", days)
+	}
+
+	days = GroupByDay(events, nil)
+	if len(days) != 2 || filterIDs(days["2023-11-14"]) != "[1]" || filterIDs(days["2023-11-15"]) != "[2 3]" {
+		t.Errorf("Days in UTC = %v", days)
+	}
+}
+
+func TestCountByField(t *testing.T) {
+	events := []Event{
+		NewEvent(testCWEvent("1", "s", `{"msg":"a","status":500,"request":{"method":"GET"}}`, 1), "g"),
+		NewEvent(testCWEvent("2", "s", `{"msg":"b","status":"500","request":{"method":"POST"}}`, 1), "g"),
+		NewEvent(testCWEvent("3", "s", `{"msg":"c","status":200,"request":{"method":"GET"}}`, 1), "g"),
+		NewEvent(testCWEvent("4", "s", `plain`, 1), "g"),
+	}
+
+	// numbers and strings holding them are counted together
+	if got := CountByField(events, "status"); len(got) != 3 || got["500"] != 2 || got["200"] != 1 || got[MissingValue] != 1 {
+		t.Errorf("Counts by status = %v", got)
+	}
+	if got := CountByField(events, "request.method"); len(got) != 3 || got["GET"] != 2 || got["POST"] != 1 || got[MissingValue] != 1 {
+		t.Errorf("Counts by request.method = %v", got)
+	}
+}
+
+func TestCoOccurrence(t *testing.T) {
+	events := []Event{
+		NewEvent(testCWEvent("1", "s", `{"msg":"a","region":"us-east-1","status":500}`, 1), "g"),
+		NewEvent(testCWEvent("2", "s", `{"msg":"b","region":"us-east-1","status":500}`, 1), "g"),
+		NewEvent(testCWEvent("3", "s", `{"msg":"c","region":"us-east-1","status":200}`, 1), "g"),
+		NewEvent(testCWEvent("4", "s", `{"msg":"d","region":"eu-west-1"}`, 1), "g"),
+		NewEvent(testCWEvent("5", "s", `plain`, 1), "g"),
+	}
+
+	counts := CoOccurrence(events, "region", "status")
+	want := map[string]map[string]int{
+		"us-east-1":  {"500": 2, "200": 1},
+		"eu-west-1":  {MissingValue: 1},
+		MissingValue: {MissingValue: 1},
+	}
+	if fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("CoOccurrence = %v, want %v", counts, want)
+	}
+}
@@ -0,0 +1,43 @@
+package lib
+
+import (
+	"regexp"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// herokuPrefix matches the prefix Logplex adds to forwarded lines, e.g.
+// "2023-11-14T12:00:00Z app[web.1]: ".  Logplex's sources are either app, for
+// output from dynos, or heroku, for the platform's own logs.
+var herokuPrefix = regexp.MustCompile(`(?s)^(\d{4}-\d{2}-\d{2}T\S+) (app|heroku)\[([\w.-]+)\]: ?(.*)$`)
+
+// ParseHeroku parses lines forwarded from Heroku's Logplex, which are prefixed
+// with a timestamp and the source and dyno that wrote them.  The dyno is used
+// as the event's stream, and the rest of the line is run through the parser
+// chain.
+func ParseHeroku(stream, message string) (SlogEvent, bool) {
+	match := herokuPrefix.FindStringSubmatch(message)
+	if match == nil {
+		return SlogEvent{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, match[1])
+	if err != nil {
+		return SlogEvent{}, false
+	}
+
+	event, ok := parseMessage(match[3], match[4])
+	if !ok {
+		event = SlogEvent{
+			Level:   ecslogs.INFO,
+			Message: match[4],
+		}
+	}
+	if event.Data == nil {
+		event.Data = map[string]interface{}{}
+	}
+	event.Data["heroku_source"] = match[2]
+	event.Time = t
+
+	return OverrideStream(event, match[3]), true
+}
@@ -0,0 +1,54 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHeroku(t *testing.T) {
+	e, ok := ParseHeroku("logs", `2023-11-14T12:00:00Z app[web.1]: {"msg":"hi","a":1}`)
+	if !ok {
+		t.Fatal("failed to parse Heroku line")
+	}
+	if e.stream != "web.1" {
+		t.Errorf("stream = %q, want web.1", e.stream)
+	}
+	if e.Message != "hi" {
+		t.Errorf("Message = %q, want hi", e.Message)
+	}
+	if e.Data["heroku_source"] != "app" {
+		t.Errorf("heroku_source = %v, want app", e.Data["heroku_source"])
+	}
+	if want := time.Date(2023, 11, 14, 12, 0, 0, 0, time.UTC); !e.Time.Equal(want) {
+		t.Errorf("Time = %s, want %s", e.Time, want)
+	}
+
+	if e, ok := ParseHeroku("logs", "2023-11-14T12:00:00Z heroku[router]: at=info method=GET"); !ok || e.stream != "router" {
+		t.Errorf("router line = %+v, %v", e, ok)
+	}
+
+	if _, ok := ParseHeroku("logs", "2024-01-01T00:00:00Z sshd[123]: Accepted key"); ok {
+		t.Error("parsed a non-Heroku line")
+	}
+}
+
+func TestNewEventStreamOverride(t *testing.T) {
+	e := NewEvent(testCWEvent("1", "logs", `2023-11-14T12:00:00Z app[web.1]: starting`, 0), "group")
+	if e.Stream != "web.1" {
+		t.Errorf("Stream = %q, want web.1", e.Stream)
+	}
+
+	e = NewEvent(testCWEvent("2", "logs", `2024-01-01T00:00:00Z sshd[123]: Accepted key`, 0), "group")
+	if e.Stream != "logs" {
+		t.Errorf("Stream = %q, want logs", e.Stream)
+	}
+
+	// a field in a JSON message is data, not an override
+	e = NewEvent(testCWEvent("3", "logs", `{"msg":"hi","_stream":"user-value"}`, 0), "group")
+	if e.Stream != "logs" {
+		t.Errorf("Stream = %q, want logs", e.Stream)
+	}
+	if e.Data["_stream"] != "user-value" {
+		t.Errorf("_stream = %v, want user-value", e.Data["_stream"])
+	}
+}
@@ -0,0 +1,41 @@
+package lib
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// indexIDBits is the number of low bits of a stable index taken from the
+// event's ID, leaving the rest for its creation time in milliseconds
+const indexIDBits = 20
+
+var (
+	// StableIndexing sets each fetched event's Index with StableIndex
+	StableIndexing = false
+)
+
+// SetStableIndexing enables or disables setting Index on fetched events
+func SetStableIndexing(enabled bool) {
+	StableIndexing = enabled
+}
+
+// StableIndex returns an index for an event from its creation time and ID.
+// Indexes increase with creation time, and events created in the same
+// millisecond are ordered by a hash of their ID, so an event's index is the
+// same whichever page it was fetched in and however many events are fetched
+// around it.  Indexes aren't guaranteed to be unique: two events created in
+// the same millisecond can have IDs whose hashes share their low 20 bits, and
+// so the same index.
+func StableIndex(creationTime time.Time, id string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	ms := creationTime.UnixNano() / int64(time.Millisecond)
+	return ms<<indexIDBits | int64(mix64(h.Sum64())&(1<<indexIDBits-1))
+}
+
+// ByIndex is used to sort events by their Index
+type ByIndex []Event
+
+func (b ByIndex) Len() int           { return len(b) }
+func (b ByIndex) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b ByIndex) Less(i, j int) bool { return b[i].Index < b[j].Index }
@@ -0,0 +1,35 @@
+package lib
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestStableIndex(t *testing.T) {
+	start := time.Date(2023, 11, 14, 12, 0, 0, 0, time.UTC)
+	event := func(ms int, id string) Event {
+		created := start.Add(time.Duration(ms) * time.Millisecond)
+		return Event{ID: id, CreationTime: created, Index: StableIndex(created, id)}
+	}
+
+	page := []Event{event(0, "a"), event(5, "b"), event(5, "c"), event(10, "d")}
+	indexes := map[string]int64{}
+	for _, e := range page {
+		indexes[e.ID] = e.Index
+	}
+
+	// the same events fetched alongside others keep their indexes
+	all := append([]Event{event(3, "e"), event(5, "f"), event(20, "g")}, page...)
+	sort.Sort(ByIndex(all))
+	for _, e := range all {
+		if index, ok := indexes[e.ID]; ok && index != e.Index {
+			t.Errorf("Index of %s changed from %d to %d", e.ID, index, e.Index)
+		}
+	}
+	for ix := 1; ix < len(all); ix++ {
+		if all[ix].CreationTime.Before(all[ix-1].CreationTime) {
+			t.Errorf("%s sorted after %s, which was created later", all[ix].ID, all[ix-1].ID)
+		}
+	}
+}
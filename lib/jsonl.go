@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// jsonlEvent is the representation of an event in JSON lines output
+type jsonlEvent struct {
+	ID           string                 `json:"id"`
+	Group        string                 `json:"group"`
+	Stream       string                 `json:"stream"`
+	Level        ecslogs.Level          `json:"level"`
+	Time         outputTime             `json:"time"`
+	CreationTime outputTime             `json:"creation_time"`
+	IngestTime   outputTime             `json:"ingest_time"`
+	Source       SourceInfo             `json:"source"`
+	Message      string                 `json:"msg"`
+	Data         map[string]interface{} `json:"data,omitempty"`
+	Structured   bool                   `json:"structured,omitempty"`
+}
+
+// WriteJSONL writes events to w as JSON lines, one event per line.  Data fields
+// are written as they were logged, nested objects included, so that ReadJSONL
+// gives back the same data.  Timestamps are written at OutputTimePrecision.
+func WriteJSONL(w io.Writer, events []Event) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, e := range events {
+		if err := enc.Encode(newJSONLEvent(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newJSONLEvent(e Event) jsonlEvent {
+	e = e.Parsed()
+	line := jsonlEvent{
+		ID:           e.ID,
+		Group:        e.Group,
+		Stream:       e.Stream,
+		Level:        e.Level,
+		Time:         outputTime{e.Time},
+		CreationTime: outputTime{e.CreationTime},
+		IngestTime:   outputTime{e.IngestTime},
+		Source:       e.Source,
+		Message:      e.Message,
+		Structured:   e.structured,
+	}
+	if len(e.Data) > 0 {
+		line.Data = e.Data
+	}
+	return line
+}
+
+// ReadJSONL reads events written by WriteJSONL.  Lines that can't be read are
+// skipped, and reported together in the returned error alongside the events
+// that could be read.
+func ReadJSONL(r io.Reader) ([]Event, error) {
+	events := []Event{}
+	var errs []error
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		event, err := decodeJSONLEvent(text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %s", lineNum, err))
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return events, errors.Join(errs...)
+}
+
+func decodeJSONLEvent(text []byte) (Event, error) {
+	dec := json.NewDecoder(bytes.NewReader(text))
+	dec.UseNumber()
+	var line jsonlEvent
+	if err := dec.Decode(&line); err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		SlogEvent: SlogEvent{
+			Level:   line.Level,
+			Time:    line.Time.Time,
+			Source:  line.Source,
+			Message: line.Message,
+			Data:    line.Data,
+		},
+		Stream:       line.Stream,
+		Group:        line.Group,
+		ID:           line.ID,
+		IngestTime:   line.IngestTime.Time,
+		CreationTime: line.CreationTime.Time,
+		structured:   line.Structured,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLRoundTrip(t *testing.T) {
+	created := time.Date(2023, 11, 14, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		NewEvent(testCWEvent("1", "s", `{"msg":"done","request":{"status":200,"path":"/"},"cached":true}`, created.UnixMilli()), "g"),
+		NewEvent(testCWEvent("2", "s", "plain", created.UnixMilli()+1), "g"),
+	}
+
+	var out bytes.Buffer
+	if err := WriteJSONL(&out, events); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadJSONL(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(events) {
+		t.Fatalf("Expected %d events, got %d", len(events), len(read))
+	}
+
+	for ix, e := range read {
+		want := events[ix]
+		if e.ID != want.ID || e.Group != want.Group || e.Stream != want.Stream || e.Message != want.Message || e.IsStructured() != want.IsStructured() {
+			t.Errorf("Read %+v, want %+v", e, want)
+		}
+		if !e.CreationTime.Equal(want.CreationTime) {
+			t.Errorf("CreationTime = %s, want %s", e.CreationTime, want.CreationTime)
+		}
+	}
+	wantData := map[string]interface{}{
+		"request": map[string]interface{}{"status": json.Number("200"), "path": "/"},
+		"cached":  true,
+	}
+	if !reflect.DeepEqual(read[0].Data, wantData) {
+		t.Errorf("Data = %v, want %v", read[0].Data, wantData)
+	}
+}
+
+func TestReadJSONLMalformed(t *testing.T) {
+	input := strings.Join([]string{
+		`{"id":"1","msg":"first"}`,
+		`not json`,
+		``,
+		`{"id":"2","msg":"second"}`,
+		`{"id":`,
+	}, "\n")
+
+	events, err := ReadJSONL(strings.NewReader(input))
+	if len(events) != 2 || events[0].ID != "1" || events[1].ID != "2" {
+		t.Errorf("Expected the readable events, got %+v", events)
+	}
+	if err == nil {
+		t.Fatal("Expected an error for the malformed lines")
+	}
+	for _, line := range []string{"line 2:", "line 5:"} {
+		if !strings.Contains(err.Error(), line) {
+			t.Errorf("Expected the error to report %s, got %s", line, err)
+		}
+	}
+}
+
+func TestJSONLDottedKeys(t *testing.T) {
+	data := map[string]interface{}{
+		"d.e": "literal",
+		"a":   "scalar",
+		"a.b": json.Number("1"),
+		"x":   map[string]interface{}{"y": "nested"},
+	}
+	e := Event{ID: "1", SlogEvent: SlogEvent{Message: "m", Data: data}}
+
+	// run it several times, since a collision would depend on map order
+	for i := 0; i < 20; i++ {
+		var out bytes.Buffer
+		if err := WriteJSONL(&out, []Event{e}); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadJSONL(&out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read[0].Data, data) {
+			t.Fatalf("Data = %v, want %v", read[0].Data, data)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Rule is a named assertion over a set of log events.  The rule fails if
+// Match returns true for any of the events.
+type Rule struct {
+	Name  string
+	Match func(Event) bool
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// WriteJUnit checks each rule against events, and writes the results to w as a
+// JUnit XML test suite with one test case per rule.  Failed test cases include
+// the messages of the events that matched the rule.
+func WriteJUnit(w io.Writer, events []Event, rules []Rule) error {
+	suite := junitTestSuite{
+		Name:      "cwlogs",
+		Tests:     len(rules),
+		TestCases: make([]junitTestCase, 0, len(rules)),
+	}
+
+	for _, rule := range rules {
+		testCase := junitTestCase{
+			Name:      rule.Name,
+			ClassName: "cwlogs",
+		}
+
+		var messages []string
+		for _, e := range events {
+			e = e.Parsed()
+			if rule.Match(e) {
+				messages = append(messages, e.Message)
+			}
+		}
+		if len(messages) > 0 {
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message:  fmt.Sprintf("%d matching events", len(messages)),
+				Contents: strings.Join(messages, "\n"),
+			}
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
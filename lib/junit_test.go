@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	events := []Event{
+		{SlogEvent: SlogEvent{Message: "started"}},
+		{SlogEvent: SlogEvent{Message: "panic: nil map"}},
+		{SlogEvent: SlogEvent{Message: "panic: index out of range"}},
+	}
+	rules := []Rule{
+		{Name: "no timeouts", Match: func(e Event) bool { return strings.Contains(e.Message, "timeout") }},
+		{Name: "no panics", Match: func(e Event) bool { return strings.HasPrefix(e.Message, "panic:") }},
+	}
+
+	var out bytes.Buffer
+	if err := WriteJUnit(&out, events, rules); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), xml.Header) {
+		t.Errorf("Expected an XML header, got %q", out.String())
+	}
+
+	var suite junitTestSuite
+	if err := xml.Unmarshal(out.Bytes(), &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 2 || suite.Failures != 1 || len(suite.TestCases) != 2 {
+		t.Fatalf("Expected 2 tests with 1 failure, got %+v", suite)
+	}
+
+	passed, failed := suite.TestCases[0], suite.TestCases[1]
+	if passed.Name != "no timeouts" || passed.Failure != nil {
+		t.Errorf("Expected 'no timeouts' to pass, got %+v", passed)
+	}
+	if failed.Name != "no panics" || failed.Failure == nil {
+		t.Fatalf("Expected 'no panics' to fail, got %+v", failed)
+	}
+	if failed.Failure.Message != "2 matching events" {
+		t.Errorf("Failure message = %q", failed.Failure.Message)
+	}
+	if failed.Failure.Contents != "panic: nil map\npanic: index out of range" {
+		t.Errorf("Failure contents = %q", failed.Failure.Contents)
+	}
+}
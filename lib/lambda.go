@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// lambdaReportPrefix starts the summary line Lambda writes after each
+// invocation
+const lambdaReportPrefix = "REPORT RequestId: "
+
+// lambdaUnits are the units of Lambda report values, and the suffix given to
+// the names of the fields holding them
+var lambdaUnits = map[string]string{
+	"ms": "_ms",
+	"MB": "_mb",
+}
+
+// ParseLambdaReport parses the REPORT lines Lambda writes at the end of each
+// invocation, e.g. "REPORT RequestId: abc Duration: 1.23 ms ...".  Each value
+// is placed in Data under its snake cased name, with a suffix for its unit
+// (e.g. duration_ms and max_memory_used_mb), and measurements as numbers.
+func ParseLambdaReport(stream, message string) (SlogEvent, bool) {
+	line := strings.TrimSpace(message)
+	if !strings.HasPrefix(line, lambdaReportPrefix) {
+		return SlogEvent{}, false
+	}
+
+	data := map[string]interface{}{}
+	for _, field := range strings.Split(strings.TrimPrefix(line, "REPORT "), "\t") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), ": ")
+		if !ok {
+			continue
+		}
+		key := lambdaFieldName(name)
+
+		parts := strings.Fields(value)
+		if len(parts) == 2 && lambdaUnits[parts[1]] != "" {
+			if n := json.Number(parts[0]); isJSONNumber(n) {
+				data[key+lambdaUnits[parts[1]]] = n
+				continue
+			}
+		}
+		data[key] = value
+	}
+	if _, ok := data["request_id"]; !ok {
+		return SlogEvent{}, false
+	}
+
+	return SlogEvent{
+		Level:   ecslogs.INFO,
+		Message: line,
+		Data:    data,
+	}, true
+}
+
+// lambdaFieldName converts a report field name such as "Max Memory Used" or
+// "XRAY TraceId" to snake case
+func lambdaFieldName(name string) string {
+	var b strings.Builder
+	prev := ' '
+	for _, r := range name {
+		switch {
+		case r == ' ':
+			b.WriteByte('_')
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			b.WriteByte('_')
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+func isJSONNumber(n json.Number) bool {
+	_, err := n.Float64()
+	return err == nil
+}
@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"sync"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+var (
+	// LazyParsing defers parsing each event's message until it is needed
+	LazyParsing = false
+)
+
+// SetLazyParsing enables or disables lazy parsing.  While enabled, NewEvent
+// only holds on to each event's raw message, and parses it the first time the
+// event's parsed contents are asked for, which saves work for events which are
+// never inspected.
+//
+// Accessor methods such as DataFlat and TimeShort parse the event as needed,
+// but SlogEvent fields (Message, Level, Data, etc.) are only filled in on the
+// event returned by Parsed, so call it before reading them directly.
+func SetLazyParsing(lazy bool) {
+	LazyParsing = lazy
+	purgeParsedEvents()
+}
+
+// lazyMessage holds the raw message of a lazily parsed event.  It is shared by
+// all copies of the event, so the message is parsed at most once.
+type lazyMessage struct {
+	once sync.Once
+	raw  string
+	// base is the event as it was built, before parsing
+	base   Event
+	parsed Event
+}
+
+// Parsed returns the event with its message parsed.  Events built while lazy
+// parsing is enabled are parsed on the first call; others are returned as-is.
+// Fields set on this copy of the event since it was built are kept, with any
+// Data fields added to those parsed from the message.  It is safe to call
+// concurrently.
+func (e Event) Parsed() Event {
+	if e.lazy == nil {
+		return e
+	}
+
+	l := e.lazy
+	l.once.Do(func() {
+		l.parsed = l.base.parse(l.raw)
+	})
+
+	parsed := l.parsed
+	parsed.Group = e.Group
+	parsed.ID = e.ID
+	parsed.IngestTime = e.IngestTime
+	parsed.CreationTime = e.CreationTime
+	parsed.Seq = e.Seq
+	parsed.Index = e.Index
+	parsed.structured = l.parsed.structured || e.structured
+
+	// the unparsed event's message fields start out empty, so anything in
+	// them was set since
+	if e.Stream != l.base.Stream {
+		parsed.Stream = e.Stream
+	}
+	var noLevel ecslogs.Level
+	if e.Level != noLevel {
+		parsed.Level = e.Level
+	}
+	if !e.Time.IsZero() {
+		parsed.Time = e.Time
+	}
+	if e.Source != (SourceInfo{}) {
+		parsed.Source = e.Source
+	}
+	if e.Message != "" {
+		parsed.Message = e.Message
+	}
+	if len(e.Data) > 0 {
+		data := make(map[string]interface{}, len(parsed.Data)+len(e.Data))
+		for k, v := range parsed.Data {
+			data[k] = v
+		}
+		for k, v := range e.Data {
+			data[k] = v
+		}
+		parsed.Data = data
+	}
+	return parsed
+}
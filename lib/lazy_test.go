@@ -0,0 +1,88 @@
+package lib
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func TestLazyParsing(t *testing.T) {
+	defer SetParsers(Parsers...)
+	calls := 0
+	SetParsers(func(stream, message string) (SlogEvent, bool) {
+		calls++
+		return ParseJSON(stream, message)
+	})
+	SetLazyParsing(true)
+	defer SetLazyParsing(false)
+
+	e := NewEvent(testCWEvent("1", "s", `{"level":"ERROR","msg":"db down","a":1}`, 0), "group")
+	if calls != 0 {
+		t.Fatalf("parsed %d times before the event was accessed, want 0", calls)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if msg := e.Parsed().Message; msg != "db down" {
+				t.Errorf("Message = %q, want db down", msg)
+			}
+			e.DataFlat()
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("parsed %d times, want 1", calls)
+	}
+}
+
+func TestLazyParsingHelpers(t *testing.T) {
+	SetLazyParsing(true)
+	defer SetLazyParsing(false)
+
+	e := NewEvent(testCWEvent("1", "s", `{"level":"ERROR","msg":"db down","a":1}`, 0), "group")
+	events := []Event{e}
+
+	if rate := ErrorRate(events, ecslogs.ERROR); rate != 1 {
+		t.Errorf("ErrorRate = %v, want 1", rate)
+	}
+	if n := Summarize(events).Levels[ecslogs.ERROR]; n != 1 {
+		t.Errorf("Summarize counted %d ERROR events, want 1", n)
+	}
+	if top := TopTemplates(events, 1); len(top) != 1 || top[0].Template != "db down" {
+		t.Errorf("TopTemplates = %v", top)
+	}
+
+	categorized := Categorize(events, []CategoryRule{{Name: "db", Match: func(e Event) bool { return e.Message == "db down" }}})
+	if got := categorized[0].Parsed().Data["_category"]; got != "db" {
+		t.Errorf("_category = %v, want db", got)
+	}
+	relative := AnnotateRelativeTo(events, e.CreationTime, time.Second)
+	if got := relative[0].Parsed().Data["_relpos"]; got != "at" {
+		t.Errorf("_relpos = %v, want at", got)
+	}
+}
+
+func TestLazyParsedKeepsChanges(t *testing.T) {
+	SetLazyParsing(true)
+	defer SetLazyParsing(false)
+
+	e := NewEvent(testCWEvent("1", "s", `{"msg":"hi","a":1}`, 0), "group")
+	e.Stream = "renamed"
+	e.Data = map[string]interface{}{"b": 2}
+
+	parsed := e.Parsed()
+	if parsed.Stream != "renamed" {
+		t.Errorf("Stream = %q, want renamed", parsed.Stream)
+	}
+	if parsed.Message != "hi" {
+		t.Errorf("Message = %q, want hi", parsed.Message)
+	}
+	if parsed.Data["a"] == nil || parsed.Data["b"] != 2 {
+		t.Errorf("Data = %v, want both a and b", parsed.Data)
+	}
+}
@@ -0,0 +1,61 @@
+package lib
+
+import (
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// LevelLocalizer renders a log level as display text
+type LevelLocalizer func(ecslogs.Level) string
+
+// LocalizeLevel is the LevelLocalizer used when displaying levels in output
+// templates
+var LocalizeLevel LevelLocalizer = EnglishLevel
+
+// SetLevelLocalizer sets the LevelLocalizer used when displaying levels.  A nil
+// localizer restores the default English names.
+func SetLevelLocalizer(localizer LevelLocalizer) {
+	if localizer == nil {
+		localizer = EnglishLevel
+	}
+	LocalizeLevel = localizer
+}
+
+// LevelName renders a log level using the current LevelLocalizer
+func LevelName(l ecslogs.Level) string {
+	return LocalizeLevel(l)
+}
+
+// EnglishLevel renders a log level using its English name
+func EnglishLevel(l ecslogs.Level) string {
+	return l.String()
+}
+
+// levelSeverity ranks log levels from least to most severe, so that levels can
+// be compared without relying on their underlying values
+func levelSeverity(l ecslogs.Level) int {
+	switch l {
+	case ecslogs.EMERG:
+		return 8
+	case ecslogs.ALERT:
+		return 7
+	case ecslogs.CRIT:
+		return 6
+	case ecslogs.ERROR:
+		return 5
+	case ecslogs.WARN:
+		return 4
+	case ecslogs.NOTICE:
+		return 3
+	case ecslogs.INFO:
+		return 2
+	case ecslogs.DEBUG:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AtLeastLevel reports whether l is at least as severe as min
+func AtLeastLevel(l ecslogs.Level, min ecslogs.Level) bool {
+	return levelSeverity(l) >= levelSeverity(min)
+}
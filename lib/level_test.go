@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/fatih/color"
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func frenchLevel(l ecslogs.Level) string {
+	switch l {
+	case ecslogs.ERROR:
+		return "ERREUR"
+	case ecslogs.WARN:
+		return "AVERTISSEMENT"
+	default:
+		return EnglishLevel(l)
+	}
+}
+
+func TestLevelLocalizer(t *testing.T) {
+	SetLevelLocalizer(frenchLevel)
+	defer SetLevelLocalizer(nil)
+
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	tmpl := template.Must(template.New("event").Funcs(template.FuncMap{
+		"colorlevel": ColorLevel,
+		"level":      LevelName,
+	}).Parse(`{{ colorlevel .Level }} ({{ level .Level }}) - {{ .Message }}`))
+
+	tests := []struct {
+		level ecslogs.Level
+		want  string
+	}{
+		{ecslogs.ERROR, "ERREUR (ERREUR) - m"},
+		{ecslogs.WARN, "AVERTISSEMENT (AVERTISSEMENT) - m"},
+		{ecslogs.INFO, ecslogs.INFO.String() + " (" + ecslogs.INFO.String() + ") - m"},
+	}
+	for _, test := range tests {
+		var out strings.Builder
+		if err := tmpl.Execute(&out, Event{SlogEvent: SlogEvent{Level: test.level, Message: "m"}}); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != test.want {
+			t.Errorf("Rendered %q, want %q", out.String(), test.want)
+		}
+	}
+
+	SetLevelLocalizer(nil)
+	if got := LevelName(ecslogs.ERROR); got != ecslogs.ERROR.String() {
+		t.Errorf("Expected a nil localizer to restore English names, got %s", got)
+	}
+}
@@ -0,0 +1,127 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultLokiBatchSize is the number of events a LokiSink pushes at once
+	DefaultLokiBatchSize = 1000
+)
+
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// LokiSink pushes events to Grafana Loki.  Events are grouped into Loki
+// streams labeled by their log group, log stream and level, and each is sent
+// as a JSON line which can be queried with Loki's json parser.
+type LokiSink struct {
+	// Client is the client used to push events, http.DefaultClient by default
+	Client *http.Client
+
+	mu        sync.Mutex
+	url       string
+	batchSize int
+	batch     []Event
+}
+
+// NewLokiSink returns a sink which pushes events to the Loki server at baseURL
+// (e.g. "http://localhost:3100") in batches of up to batchSize events, or
+// DefaultLokiBatchSize if batchSize is not positive
+func NewLokiSink(baseURL string, batchSize int) *LokiSink {
+	if batchSize <= 0 {
+		batchSize = DefaultLokiBatchSize
+	}
+	return &LokiSink{
+		Client:    http.DefaultClient,
+		url:       strings.TrimRight(baseURL, "/") + "/loki/api/v1/push",
+		batchSize: batchSize,
+	}
+}
+
+// Emit adds the event to the current batch, pushing the batch once it is full
+func (s *LokiSink) Emit(ctx context.Context, e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batch = append(s.batch, e.Parsed())
+	if len(s.batch) < s.batchSize {
+		return nil
+	}
+	return s.flush(ctx)
+}
+
+// Close pushes any events in the current batch
+func (s *LokiSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush(context.Background())
+}
+
+func (s *LokiSink) flush(ctx context.Context) error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+
+	push, err := newLokiPush(s.batch)
+	if err != nil {
+		return err
+	}
+	s.batch = s.batch[:0]
+
+	body, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, s.Client, s.url, body)
+}
+
+// newLokiPush groups events into streams by label set, with each stream's
+// entries in time order
+func newLokiPush(events []Event) (lokiPush, error) {
+	sorted := make([]Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	push := lokiPush{Streams: []lokiStream{}}
+	streams := map[[3]string]int{}
+	for _, e := range sorted {
+		key := [3]string{e.Group, e.Stream, e.Level.String()}
+		ix, ok := streams[key]
+		if !ok {
+			ix = len(push.Streams)
+			streams[key] = ix
+			push.Streams = append(push.Streams, lokiStream{
+				Stream: map[string]string{
+					"log_group":  key[0],
+					"log_stream": key[1],
+					"level":      key[2],
+				},
+			})
+		}
+
+		line, err := json.Marshal(newJSONLEvent(e))
+		if err != nil {
+			return lokiPush{}, err
+		}
+		push.Streams[ix].Values = append(push.Streams[ix].Values, [2]string{
+			strconv.FormatInt(e.Time.UnixNano(), 10),
+			string(line),
+		})
+	}
+	return push, nil
+}
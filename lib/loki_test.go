@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestLokiSink(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		pushes []lokiPush
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/loki/api/v1/push" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var push lokiPush
+		if err := json.NewDecoder(r.Body).Decode(&push); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		pushes = append(pushes, push)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewLokiSink(server.URL+"/", 2)
+	events := []Event{
+		NewEvent(testCWEvent("1", "a", `{"time":"2023-11-14T22:13:20.000000002Z","msg":"second"}`, 1), "g"),
+		NewEvent(testCWEvent("2", "a", `{"time":"2023-11-14T22:13:20.000000001Z","msg":"first"}`, 1), "g"),
+		NewEvent(testCWEvent("3", "b", `{"time":"2023-11-14T22:13:21Z","level":"ERROR","msg":"failed"}`, 1), "g"),
+	}
+	ctx := context.Background()
+	for _, e := range events {
+		if err := sink.Emit(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(pushes) != 1 {
+		t.Fatalf("Expected a push once the batch was full, got %d", len(pushes))
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(pushes) != 2 {
+		t.Fatalf("Expected the rest of the batch to be pushed on close, got %d pushes", len(pushes))
+	}
+
+	first := pushes[0]
+	if len(first.Streams) != 1 {
+		t.Fatalf("Expected events with the same labels in one stream, got %v", first.Streams)
+	}
+	labels := first.Streams[0].Stream
+	if labels["log_group"] != "g" || labels["log_stream"] != "a" || labels["level"] != events[0].Level.String() || len(labels) != 3 {
+		t.Errorf("Unexpected labels %v", labels)
+	}
+	values := first.Streams[0].Values
+	if len(values) != 2 || values[0][0] != "1700000000000000001" || values[1][0] != "1700000000000000002" {
+		t.Errorf("Expected entries in time order with nanosecond timestamps, got %v", values)
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(values[0][1]), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["msg"] != "first" {
+		t.Errorf("Unexpected line %s", values[0][1])
+	}
+
+	second := pushes[1].Streams
+	if len(second) != 1 || second[0].Stream["log_stream"] != "b" || second[0].Stream["level"] != events[2].Level.String() {
+		t.Errorf("Unexpected streams %v", second)
+	}
+}
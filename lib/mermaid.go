@@ -0,0 +1,54 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// mermaidEscaper escapes text that would otherwise end or break a statement
+// in a Mermaid diagram
+var mermaidEscaper = strings.NewReplacer(
+	"#", "#35;",
+	";", "#59;",
+	"\r\n", "<br/>",
+	"\n", "<br/>",
+)
+
+// WriteMermaidSequence writes events to w as a Mermaid sequence diagram, with
+// a lane for each participant (as chosen by the participant func, e.g. by
+// stream) and a note on that lane for each event, in creation time order
+func WriteMermaidSequence(w io.Writer, events []Event, participant func(Event) string) error {
+	sorted := make([]Event, len(events))
+	for ix, e := range events {
+		sorted[ix] = e.Parsed()
+	}
+	sort.Stable(ByCreationTime(sorted))
+
+	ids := map[string]string{}
+	var names []string
+	for _, e := range sorted {
+		name := participant(e)
+		if _, ok := ids[name]; !ok {
+			ids[name] = fmt.Sprintf("p%d", len(names))
+			names = append(names, name)
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "sequenceDiagram")
+	for _, name := range names {
+		fmt.Fprintf(out, "    participant %s as %s\n", ids[name], mermaidEscaper.Replace(name))
+	}
+	for _, e := range sorted {
+		fmt.Fprintf(out, "    Note over %s: %s %s %s\n",
+			ids[participant(e)],
+			e.CreationTime.Local().Format(ShortTimeFormat),
+			LevelName(e.Level),
+			mermaidEscaper.Replace(e.Message),
+		)
+	}
+	return out.Flush()
+}
@@ -0,0 +1,39 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func TestWriteMermaidSequence(t *testing.T) {
+	base := time.Unix(60000, 0)
+	events := []Event{
+		{Stream: "worker", CreationTime: base.Add(2 * time.Second), SlogEvent: SlogEvent{Level: ecslogs.ERROR, Message: "job failed; retrying\nattempt #2"}},
+		{Stream: "api", CreationTime: base, SlogEvent: SlogEvent{Level: ecslogs.INFO, Message: "enqueued job"}},
+		{Stream: "worker", CreationTime: base.Add(time.Second), SlogEvent: SlogEvent{Level: ecslogs.INFO, Message: "started job"}},
+	}
+
+	var out strings.Builder
+	if err := WriteMermaidSequence(&out, events, func(e Event) string { return e.Stream }); err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(offset time.Duration) string {
+		return base.Add(offset).Local().Format(ShortTimeFormat)
+	}
+	want := strings.Join([]string{
+		"sequenceDiagram",
+		"    participant p0 as api",
+		"    participant p1 as worker",
+		"    Note over p0: " + at(0) + " " + LevelName(ecslogs.INFO) + " enqueued job",
+		"    Note over p1: " + at(time.Second) + " " + LevelName(ecslogs.INFO) + " started job",
+		"    Note over p1: " + at(2*time.Second) + " " + LevelName(ecslogs.ERROR) + " job failed#59; retrying<br/>attempt #35;2",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Errorf("Diagram =\n%s\nwant\n%s", out.String(), want)
+	}
+}
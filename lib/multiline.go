@@ -0,0 +1,118 @@
+package lib
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+var (
+	// MaxMultilineLines is the maximum number of lines a single JSON event can
+	// span when coalescing multiline events.  Zero disables coalescing.
+	MaxMultilineLines = 0
+)
+
+// SetMaxMultilineLines enables coalescing of JSON events that were pretty
+// printed across multiple lines, allowing up to max lines per event
+func SetMaxMultilineLines(max int) {
+	MaxMultilineLines = max
+}
+
+// Coalescer joins log events which hold pieces of a single pretty printed
+// JSON object back into one event.  Events are tracked per log stream, since
+// streams are interleaved when fetching.
+type Coalescer struct {
+	maxLines int
+	pending  map[string][]cloudwatchlogs.FilteredLogEvent
+}
+
+// NewCoalescer returns a Coalescer that gives up on an event once it spans
+// more than maxLines lines
+func NewCoalescer(maxLines int) *Coalescer {
+	return &Coalescer{
+		maxLines: maxLines,
+		pending:  map[string][]cloudwatchlogs.FilteredLogEvent{},
+	}
+}
+
+// Add takes the next event read from a stream and returns any events that are
+// ready to be emitted.  Lines that are part of an incomplete JSON object are
+// held until the object's braces balance, and then returned as a single event
+// with the ID and timestamps of the first line.  If the object grows past the
+// line limit, the held lines are returned unmodified.
+func (c *Coalescer) Add(event cloudwatchlogs.FilteredLogEvent) []cloudwatchlogs.FilteredLogEvent {
+	stream := aws.StringValue(event.LogStreamName)
+	lines, ok := c.pending[stream]
+	if !ok {
+		if depth := braceDepth(aws.StringValue(event.Message)); depth <= 0 ||
+			!strings.HasPrefix(strings.TrimSpace(aws.StringValue(event.Message)), "{") {
+			return []cloudwatchlogs.FilteredLogEvent{event}
+		}
+		c.pending[stream] = []cloudwatchlogs.FilteredLogEvent{event}
+		return nil
+	}
+
+	lines = append(lines, event)
+	if len(lines) > c.maxLines {
+		delete(c.pending, stream)
+		return lines
+	}
+
+	joined := joinMessages(lines)
+	if braceDepth(joined) > 0 {
+		c.pending[stream] = lines
+		return nil
+	}
+
+	delete(c.pending, stream)
+	combined := lines[0]
+	combined.Message = aws.String(joined)
+	return []cloudwatchlogs.FilteredLogEvent{combined}
+}
+
+// Flush returns any held lines, unmodified, and resets the Coalescer
+func (c *Coalescer) Flush() []cloudwatchlogs.FilteredLogEvent {
+	var events []cloudwatchlogs.FilteredLogEvent
+	for stream, lines := range c.pending {
+		events = append(events, lines...)
+		delete(c.pending, stream)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return aws.Int64Value(events[i].Timestamp) < aws.Int64Value(events[j].Timestamp)
+	})
+	return events
+}
+
+func joinMessages(events []cloudwatchlogs.FilteredLogEvent) string {
+	messages := make([]string, 0, len(events))
+	for _, e := range events {
+		messages = append(messages, aws.StringValue(e.Message))
+	}
+	return strings.Join(messages, "\n")
+}
+
+// braceDepth returns the number of unclosed braces in s, ignoring any braces
+// found inside of JSON strings
+func braceDepth(s string) int {
+	depth := 0
+	inString := false
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && r == '\\':
+			escaped = true
+		case r == '"':
+			inString = !inString
+		case inString:
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		}
+	}
+	return depth
+}
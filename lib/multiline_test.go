@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+func TestCoalescer(t *testing.T) {
+	c := NewCoalescer(10)
+	var out []cloudwatchlogs.FilteredLogEvent
+	lines := []string{"plain", "{", `  "msg": "a } b",`, `  "request": {"retries": 1}`, "}", "after"}
+	for ix, line := range lines {
+		out = append(out, c.Add(testCWEvent(fmt.Sprint(ix), "s", line, int64(ix)))...)
+	}
+	if len(out) != 3 {
+		t.Fatalf("Expected the pretty printed lines to become one event, got %d events", len(out))
+	}
+	if aws.StringValue(out[1].EventId) != "1" || aws.Int64Value(out[1].Timestamp) != 1 {
+		t.Errorf("Expected the ID and timestamp of the first line, got %s at %d", aws.StringValue(out[1].EventId), aws.Int64Value(out[1].Timestamp))
+	}
+
+	e := NewEvent(out[1], "g")
+	if e.Message != "a } b" {
+		t.Errorf("Message = %q", e.Message)
+	}
+	if request, ok := e.Data["request"].(map[string]interface{}); !ok || request["retries"] != json.Number("1") {
+		t.Errorf("Unexpected data %v", e.Data)
+	}
+	if flushed := c.Flush(); len(flushed) != 0 {
+		t.Errorf("Expected nothing left to flush, got %d events", len(flushed))
+	}
+}
+
+func TestCoalescerMaxLines(t *testing.T) {
+	c := NewCoalescer(2)
+	var out []cloudwatchlogs.FilteredLogEvent
+	for ix, line := range []string{"{", `"a": 1,`, `"b": 2`} {
+		out = append(out, c.Add(testCWEvent(fmt.Sprint(ix), "s", line, int64(ix)))...)
+	}
+	if len(out) != 3 {
+		t.Fatalf("Expected the lines to be returned as-is past the limit, got %d events", len(out))
+	}
+	for ix, e := range out {
+		if aws.StringValue(e.EventId) != fmt.Sprint(ix) {
+			t.Errorf("Event %d has ID %s", ix, aws.StringValue(e.EventId))
+		}
+	}
+
+	// incomplete objects are returned unmodified by Flush
+	c.Add(testCWEvent("x", "s", "{", 1))
+	if flushed := c.Flush(); len(flushed) != 1 || aws.StringValue(flushed[0].Message) != "{" {
+		t.Errorf("Unexpected flushed events %v", flushed)
+	}
+}
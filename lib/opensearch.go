@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// OpenSearchTimeFormat is the format used for the @timestamp field of
+// documents written by WriteOpenSearch.  It is always rendered in UTC with
+// millisecond precision, so every document maps to the same date format.  It
+// is used unless OutputTimePrecision is set.
+const OpenSearchTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+type openSearchAction struct {
+	Index openSearchIndex `json:"index"`
+}
+
+type openSearchIndex struct {
+	Index string `json:"_index"`
+	ID    string `json:"_id,omitempty"`
+}
+
+type openSearchDocument struct {
+	Timestamp  interface{}            `json:"@timestamp"`
+	Level      string                 `json:"level"`
+	Message    string                 `json:"message"`
+	Group      string                 `json:"log_group"`
+	Stream     string                 `json:"log_stream"`
+	IngestTime interface{}            `json:"ingest_time"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
+
+// WriteOpenSearch writes events to w in the OpenSearch (and Elasticsearch)
+// bulk API format, indexing each event into index by its ID.  Data fields keep
+// the types they were logged with, so numbers and booleans are written as JSON
+// numbers and booleans rather than strings, avoiding mapping conflicts.
+func WriteOpenSearch(w io.Writer, events []Event, index string) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	for _, e := range events {
+		e = e.Parsed()
+		action := openSearchAction{
+			Index: openSearchIndex{
+				Index: index,
+				ID:    e.ID,
+			},
+		}
+		if err := enc.Encode(action); err != nil {
+			return err
+		}
+
+		doc := openSearchDocument{
+			Timestamp:  formatOpenSearchTime(e.Time),
+			Level:      e.Level.String(),
+			Message:    e.Message,
+			Group:      e.Group,
+			Stream:     e.Stream,
+			IngestTime: formatOpenSearchTime(e.IngestTime),
+			Data:       e.Data,
+		}
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatOpenSearchTime(t time.Time) interface{} {
+	return OutputTimePrecision.formatTime(t.UTC(), OpenSearchTimeFormat)
+}
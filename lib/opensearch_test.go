@@ -0,0 +1,41 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteOpenSearch(t *testing.T) {
+	e := NewEvent(testCWEvent("1", "s", `{"msg":"done","status":200,"duration":1.5,"cached":true,"user":"42","request":{"path":"/"}}`, 1700000000000), "g")
+
+	var out bytes.Buffer
+	if err := WriteOpenSearch(&out, []Event{e}, "logs"); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(&out)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 2 {
+		t.Fatalf("Expected an action and a document, got %q", lines)
+	}
+	if lines[0] != `{"index":{"_index":"logs","_id":"1"}}` {
+		t.Errorf("Unexpected action %s", lines[0])
+	}
+	for _, field := range []string{`"status":200`, `"duration":1.5`, `"cached":true`, `"user":"42"`, `"request":{"path":"/"}`, `"@timestamp":"2023-11-14T22:13:20.000Z"`} {
+		if !strings.Contains(lines[1], field) {
+			t.Errorf("Expected %s in document %s", field, lines[1])
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	e := NewEvent(testCWEvent("1", "s", `{"msg":"done","request":{"path":"/","retries":2}}`, 1), "g")
+	if got := JSON(e.Data["request"]); got != `{"path":"/","retries":2}` {
+		t.Errorf("JSON(request) = %s", got)
+	}
+}
@@ -0,0 +1,87 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+// ToOTelRecord converts an event to an OpenTelemetry log record.  The message
+// is used as the body, and the flattened data fields become attributes, along
+// with the event's stream and ID.
+func ToOTelRecord(e Event) *logspb.LogRecord {
+	e = e.Parsed()
+
+	flat := e.DataFlat()
+	attributes := make([]*commonpb.KeyValue, 0, len(flat)+2)
+	attributes = append(attributes,
+		otelAttribute("aws.log.stream.name", e.Stream),
+		otelAttribute("aws.log.event.id", e.ID),
+	)
+	for key, value := range flat {
+		attributes = append(attributes, otelAttribute(key, value))
+	}
+
+	return &logspb.LogRecord{
+		TimeUnixNano:         uint64(e.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(e.IngestTime.UnixNano()),
+		SeverityNumber:       otelSeverity(e.Level),
+		SeverityText:         e.Level.String(),
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: e.Message}},
+		Attributes:           attributes,
+	}
+}
+
+func otelSeverity(l ecslogs.Level) logspb.SeverityNumber {
+	switch l {
+	case ecslogs.DEBUG:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	case ecslogs.INFO:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case ecslogs.NOTICE:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO2
+	case ecslogs.WARN:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case ecslogs.ERROR:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	case ecslogs.CRIT:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL
+	case ecslogs.ALERT:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL2
+	case ecslogs.EMERG:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL3
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED
+	}
+}
+
+// otelAttribute converts a data value to an attribute, keeping its type
+func otelAttribute(key string, value interface{}) *commonpb.KeyValue {
+	var v commonpb.AnyValue
+	switch x := value.(type) {
+	case string:
+		v.Value = &commonpb.AnyValue_StringValue{StringValue: x}
+	case bool:
+		v.Value = &commonpb.AnyValue_BoolValue{BoolValue: x}
+	case int:
+		v.Value = &commonpb.AnyValue_IntValue{IntValue: int64(x)}
+	case int64:
+		v.Value = &commonpb.AnyValue_IntValue{IntValue: x}
+	case float64:
+		v.Value = &commonpb.AnyValue_DoubleValue{DoubleValue: x}
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			v.Value = &commonpb.AnyValue_IntValue{IntValue: i}
+		} else if f, err := x.Float64(); err == nil {
+			v.Value = &commonpb.AnyValue_DoubleValue{DoubleValue: f}
+		} else {
+			v.Value = &commonpb.AnyValue_StringValue{StringValue: x.String()}
+		}
+	default:
+		v.Value = &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(x)}
+	}
+	return &commonpb.KeyValue{Key: key, Value: &v}
+}
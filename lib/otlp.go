@@ -0,0 +1,143 @@
+package lib
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultOTLPBatchSize is the number of events an OTLPSink exports at once
+	DefaultOTLPBatchSize = 512
+	// OTLPMaxRetries is the number of times an export is retried after a
+	// retryable failure
+	OTLPMaxRetries = 5
+)
+
+// OTLPSink exports events to an OpenTelemetry collector (or any other OTLP
+// logs receiver) over gRPC
+type OTLPSink struct {
+	mu        sync.Mutex
+	conn      *grpc.ClientConn
+	client    collogspb.LogsServiceClient
+	batchSize int
+	batch     []Event
+}
+
+// NewOTLPSink returns a sink which exports events to the OTLP gRPC endpoint
+// (e.g. "localhost:4317") in batches of up to batchSize events, or
+// DefaultOTLPBatchSize if batchSize is not positive.  Without any dial
+// options, the connection is made without TLS.
+func NewOTLPSink(endpoint string, batchSize int, opts ...grpc.DialOption) (*OTLPSink, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultOTLPBatchSize
+	}
+
+	conn, err := grpc.NewClient(endpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OTLPSink{
+		conn:      conn,
+		client:    collogspb.NewLogsServiceClient(conn),
+		batchSize: batchSize,
+	}, nil
+}
+
+// Emit adds the event to the current batch, exporting the batch once it is
+// full
+func (s *OTLPSink) Emit(ctx context.Context, e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batch = append(s.batch, e)
+	if len(s.batch) < s.batchSize {
+		return nil
+	}
+	return s.flush(ctx)
+}
+
+// Close exports any events in the current batch and closes the connection
+func (s *OTLPSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.flush(context.Background())
+	if closeErr := s.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (s *OTLPSink) flush(ctx context.Context) error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+	req := newOTLPRequest(s.batch)
+	s.batch = s.batch[:0]
+
+	backoff := 100 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		_, err := s.client.Export(ctx, req)
+		if err == nil || attempt >= OTLPMaxRetries || !retryableOTLPError(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// newOTLPRequest converts events to an export request, with a resource for
+// each log group
+func newOTLPRequest(events []Event) *collogspb.ExportLogsServiceRequest {
+	req := &collogspb.ExportLogsServiceRequest{}
+	groups := map[string]*logspb.ScopeLogs{}
+
+	for _, e := range events {
+		scope, ok := groups[e.Group]
+		if !ok {
+			scope = &logspb.ScopeLogs{
+				Scope: &commonpb.InstrumentationScope{Name: "github.com/runreveal/cwlogs"},
+			}
+			groups[e.Group] = scope
+			req.ResourceLogs = append(req.ResourceLogs, &logspb.ResourceLogs{
+				Resource: &resourcepb.Resource{
+					Attributes: []*commonpb.KeyValue{otelAttribute("aws.log.group.name", e.Group)},
+				},
+				ScopeLogs: []*logspb.ScopeLogs{scope},
+			})
+		}
+		scope.LogRecords = append(scope.LogRecords, ToOTelRecord(e))
+	}
+	return req
+}
+
+// retryableOTLPError reports whether an export failure is worth retrying, per
+// the OTLP specification
+func retryableOTLPError(err error) bool {
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded, codes.Aborted, codes.OutOfRange,
+		codes.Unavailable, codes.DataLoss, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,128 @@
+package lib
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testLogsServer records export requests, failing the first failures of them
+// as unavailable
+type testLogsServer struct {
+	collogspb.UnimplementedLogsServiceServer
+
+	mu       sync.Mutex
+	failures int
+	attempts int
+	requests []*collogspb.ExportLogsServiceRequest
+}
+
+func (s *testLogsServer) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+	if s.attempts <= s.failures {
+		return nil, status.Error(codes.Unavailable, "try again")
+	}
+	s.requests = append(s.requests, req)
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+func newTestOTLPSink(t *testing.T, server *testLogsServer, batchSize int) *OTLPSink {
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	collogspb.RegisterLogsServiceServer(srv, server)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	sink, err := NewOTLPSink("passthrough:///bufconn", batchSize,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sink
+}
+
+func TestOTLPSink(t *testing.T) {
+	server := &testLogsServer{failures: 1}
+	sink := newTestOTLPSink(t, server, 2)
+
+	ctx := context.Background()
+	messages := []string{
+		`{"level":"ERROR","msg":"failed","status":500,"retry":true}`,
+		`{"msg":"ok"}`,
+		`plain`,
+	}
+	for ix, message := range messages {
+		if err := sink.Emit(ctx, NewEvent(testCWEvent(string(rune('a'+ix)), "s", message, 1700000000000), "g")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first export is retried after failing, and the remaining event is
+	// exported on close
+	if server.attempts != 3 || len(server.requests) != 2 {
+		t.Fatalf("Expected 3 attempts and 2 exports, got %d and %d", server.attempts, len(server.requests))
+	}
+
+	var records []*logspb.LogRecord
+	for _, req := range server.requests {
+		for _, resource := range req.ResourceLogs {
+			if group := resource.Resource.Attributes[0]; group.Key != "aws.log.group.name" || group.Value.GetStringValue() != "g" {
+				t.Errorf("Unexpected resource attribute %v", group)
+			}
+			for _, scope := range resource.ScopeLogs {
+				records = append(records, scope.LogRecords...)
+			}
+		}
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	failed := records[0]
+	if failed.SeverityNumber != logspb.SeverityNumber_SEVERITY_NUMBER_ERROR || failed.Body.GetStringValue() != "failed" {
+		t.Errorf("Unexpected record %v", failed)
+	}
+	for _, attribute := range failed.Attributes {
+		var ok bool
+		switch attribute.Key {
+		case "aws.log.stream.name":
+			ok = attribute.Value.GetStringValue() == "s"
+		case "aws.log.event.id":
+			ok = attribute.Value.GetStringValue() == "a"
+		case "status":
+			ok = attribute.Value.GetIntValue() == 500
+		case "retry":
+			ok = attribute.Value.GetBoolValue()
+		default:
+			ok = true
+		}
+		if !ok {
+			t.Errorf("Unexpected attribute %s = %v", attribute.Key, attribute.Value)
+		}
+	}
+	if len(failed.Attributes) != 4 {
+		t.Errorf("Expected 4 attributes, got %v", failed.Attributes)
+	}
+	if records[2].Body.GetStringValue() != "plain" {
+		t.Errorf("Unexpected body %v", records[2].Body)
+	}
+}
@@ -0,0 +1,135 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+// Parser decodes a raw log message, written to the named stream, into a
+// SlogEvent.  It returns false if the message is not in a format the parser
+// understands, in which case the next parser in the chain is tried.  Most
+// parsers ignore the stream, but it lets parsers of formats with headers
+// (e.g. W3C) keep track of each stream's header separately.
+//
+// Parsers which learn the real source of a message (e.g. a forwarded log) can
+// replace the event's stream name with OverrideStream.
+type Parser func(stream, message string) (SlogEvent, bool)
+
+// OverrideStream returns a copy of event which, when returned by a parser,
+// replaces the stream name of the Event built from it
+func OverrideStream(event SlogEvent, stream string) SlogEvent {
+	event.stream = stream
+	return event
+}
+
+// Parsers is the chain of parsers NewEvent tries, in order, on each message
+// from groups without a chain in GroupParserChains.  If none of them match,
+// the message is used as-is.
+var Parsers []Parser
+
+func init() {
+	// Set here rather than in the declaration, since parsers which unwrap
+	// other formats run their contents back through the chain
+	Parsers = []Parser{ParseDocker, ParseFluentBit, ParseJSON, ParseSyslog, ParseHeroku}
+}
+
+// SetParsers replaces the chain of parsers used by NewEvent
+func SetParsers(parsers ...Parser) {
+	Parsers = parsers
+	purgeParsedEvents()
+}
+
+// GroupParsers is the parser chain used for events from log groups whose name
+// matches Pattern, a glob as understood by path.Match (e.g. "/aws/lambda/*")
+type GroupParsers struct {
+	Pattern string
+	Parsers []Parser
+}
+
+// GroupParserChains are the parser chains for particular log groups, checked
+// in order by NewEvent.  Events from groups which match none of them use
+// Parsers.
+var GroupParserChains []GroupParsers
+
+// SetGroupParsers sets the parser chain used for log groups matching pattern,
+// replacing any chain previously set for the same pattern.  The chain replaces
+// Parsers entirely for those groups, so include the default parsers in it to
+// fall back on them.  Giving no parsers removes the pattern's chain.
+func SetGroupParsers(pattern string, parsers ...Parser) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("Invalid group pattern '%s': %s", pattern, err)
+	}
+
+	chains := []GroupParsers{}
+	replaced := false
+	for _, chain := range GroupParserChains {
+		if chain.Pattern != pattern {
+			chains = append(chains, chain)
+			continue
+		}
+		if len(parsers) > 0 {
+			chains = append(chains, GroupParsers{Pattern: pattern, Parsers: parsers})
+		}
+		replaced = true
+	}
+	if !replaced && len(parsers) > 0 {
+		chains = append(chains, GroupParsers{Pattern: pattern, Parsers: parsers})
+	}
+	GroupParserChains = chains
+	purgeParsedEvents()
+	return nil
+}
+
+// ParsersForGroup returns the parser chain used for events from group: that
+// of the first entry in GroupParserChains matching it, or Parsers if none do
+func ParsersForGroup(group string) []Parser {
+	for _, chain := range GroupParserChains {
+		if ok, _ := path.Match(chain.Pattern, group); ok {
+			return chain.Parsers
+		}
+	}
+	return Parsers
+}
+
+// NewParser returns the parser with the given name, for parsers of formats
+// which aren't tried by default
+func NewParser(name string) (Parser, error) {
+	switch name {
+	case "alb":
+		return ParseALB, nil
+	case "w3c":
+		return NewW3CParser().Parse, nil
+	case "postgres-csv":
+		return ParsePostgresCSV, nil
+	case "lambda-report":
+		return ParseLambdaReport, nil
+	default:
+		return nil, fmt.Errorf("Unknown parser '%s'", name)
+	}
+}
+
+// ParseJSON parses messages written as a JSON object, such as those produced
+// by log/slog's JSON handler
+func ParseJSON(stream, message string) (SlogEvent, bool) {
+	var event SlogEvent
+	if err := json.Unmarshal([]byte(message), &event); err != nil {
+		return SlogEvent{}, false
+	}
+	return event, true
+}
+
+// parseMessage runs message through the default parser chain.  Parsers which
+// unwrap other formats use it for their contents, whichever chain they're in.
+func parseMessage(stream, message string) (SlogEvent, bool) {
+	return parseWith(Parsers, stream, message)
+}
+
+func parseWith(parsers []Parser, stream, message string) (SlogEvent, bool) {
+	for _, parse := range parsers {
+		if event, ok := parse(stream, message); ok {
+			return event, true
+		}
+	}
+	return SlogEvent{}, false
+}
@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSetGroupParsers(t *testing.T) {
+	defer func() { GroupParserChains = nil }()
+
+	if err := SetGroupParsers("/aws/lambda/*", append([]Parser{ParseLambdaReport}, Parsers...)...); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetGroupParsers("/alb/*", ParseALB); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetGroupParsers("[", ParseALB); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+
+	report := "REPORT RequestId: 3604209a-e9a3-11e6-939a-754dd98c7be3\tDuration: 12.34 ms\tBilled Duration: 13 ms\tMemory Size: 128 MB\tMax Memory Used: 18 MB\t\n"
+	access := `2015-05-13T23:39:43.945958Z my-loadbalancer 192.168.131.39:2817 10.0.0.1:80 0.000073 0.001048 0.000057 404 404 0 29 "GET http://www.example.com:80/ HTTP/1.1" "curl/7.38.0" - -`
+	tests := []struct {
+		name       string
+		group      string
+		message    string
+		structured bool
+	}{
+		{"lambda report", "/aws/lambda/fn", report, true},
+		{"lambda falls back on the defaults", "/aws/lambda/fn", `{"msg":"hi"}`, true},
+		{"lambda access log", "/aws/lambda/fn", access, false},
+		{"alb access log", "/alb/web", access, true},
+		{"alb report", "/alb/web", report, false},
+		{"alb only uses its chain", "/alb/web", `{"msg":"hi"}`, false},
+		{"other report", "/other", report, false},
+		{"other json", "/other", `{"msg":"hi"}`, true},
+	}
+	for ix, test := range tests {
+		e := NewEvent(testCWEvent(string(rune('a'+ix)), "s", test.message, 1), test.group)
+		if e.IsStructured() != test.structured {
+			t.Errorf("%s: IsStructured() = %v, want %v", test.name, e.IsStructured(), test.structured)
+		}
+	}
+
+	e := NewEvent(testCWEvent("lambda", "s", report, 1), "/aws/lambda/fn")
+	if e.Data["duration_ms"] != json.Number("12.34") || e.Data["max_memory_used_mb"] != json.Number("18") {
+		t.Errorf("Unexpected report data %v", e.Data)
+	}
+	e = NewEvent(testCWEvent("alb", "s", access, 1), "/alb/web")
+	if e.Message != "GET http://www.example.com:80/ HTTP/1.1" {
+		t.Errorf("Unexpected access log message '%s'", e.Message)
+	}
+
+	if err := SetGroupParsers("/alb/*"); err != nil {
+		t.Fatal(err)
+	}
+	if len(GroupParserChains) != 1 || GroupParserChains[0].Pattern != "/aws/lambda/*" {
+		t.Errorf("Expected only the lambda chain to remain, got %v", GroupParserChains)
+	}
+	if chain := ParsersForGroup("/alb/web"); len(chain) != len(Parsers) {
+		t.Errorf("Expected /alb/web to use the default chain, got %d parsers", len(chain))
+	}
+}
@@ -0,0 +1,153 @@
+package lib
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// postgresColumns are the columns of Postgres' csvlog format.  Servers before
+// Postgres 13 write only the first 23, and later versions add the rest.
+var postgresColumns = []string{
+	"log_time",
+	"user_name",
+	"database_name",
+	"process_id",
+	"connection_from",
+	"session_id",
+	"session_line_num",
+	"command_tag",
+	"session_start_time",
+	"virtual_transaction_id",
+	"transaction_id",
+	"error_severity",
+	"sql_state_code",
+	"message",
+	"detail",
+	"hint",
+	"internal_query",
+	"internal_query_pos",
+	"context",
+	"query",
+	"query_pos",
+	"location",
+	"application_name",
+	"backend_type",
+	"leader_pid",
+	"query_id",
+}
+
+const postgresMinColumns = 23
+
+// postgresTimeFormat is the layout of csvlog timestamps, before the zone
+const postgresTimeFormat = "2006-01-02 15:04:05"
+
+// postgresOffsetFormats are the layouts of numeric time zone offsets Postgres
+// writes, depending on the zone
+var postgresOffsetFormats = []string{"-07", "-0700", "-07:00"}
+
+// ParsePostgresCSV parses entries from Postgres' csvlog format.  The severity
+// is used as the level, log_time as the timestamp, and the other non-empty
+// columns are placed in Data by column name.  Quoted columns may contain
+// commas and newlines, so an entry can span several lines.
+//
+// Time zone abbreviations other than UTC and GMT are ambiguous, so log times
+// in those zones aren't used as the timestamp, and are left in Data instead.
+func ParsePostgresCSV(stream, message string) (SlogEvent, bool) {
+	r := csv.NewReader(strings.NewReader(message))
+	r.FieldsPerRecord = -1
+	record, err := r.Read()
+	if err != nil || len(record) < postgresMinColumns || len(record) > len(postgresColumns) {
+		return SlogEvent{}, false
+	}
+	if _, err := r.Read(); err != io.EOF {
+		return SlogEvent{}, false
+	}
+
+	level, ok := postgresLevel(record[11])
+	if !ok {
+		return SlogEvent{}, false
+	}
+
+	t, known, ok := parsePostgresTime(record[0])
+	if !ok {
+		return SlogEvent{}, false
+	}
+
+	event := SlogEvent{
+		Level:   level,
+		Time:    t,
+		Message: record[13],
+		Data:    map[string]interface{}{},
+	}
+	for ix, value := range record {
+		switch column := postgresColumns[ix]; column {
+		case "message":
+		case "log_time":
+			if !known && value != "" {
+				event.Data[column] = value
+			}
+		default:
+			if value != "" {
+				event.Data[column] = value
+			}
+		}
+	}
+
+	return event, true
+}
+
+// parsePostgresTime parses a csvlog timestamp such as "2024-01-01
+// 12:00:00.123 UTC".  It returns false if the timestamp is malformed, and
+// known is false if it is in a zone which can't be resolved to an offset.
+// Fractional seconds are accepted even though the layouts have none.
+func parsePostgresTime(value string) (t time.Time, known bool, ok bool) {
+	clock, zone := value, ""
+	if ix := strings.LastIndexByte(value, ' '); ix > strings.IndexByte(value, ' ') {
+		clock, zone = value[:ix], value[ix+1:]
+	}
+
+	if strings.HasPrefix(zone, "+") || strings.HasPrefix(zone, "-") {
+		for _, format := range postgresOffsetFormats {
+			if t, err := time.Parse(postgresTimeFormat+" "+format, value); err == nil {
+				return t, true, true
+			}
+		}
+		return time.Time{}, false, false
+	}
+
+	t, err := time.Parse(postgresTimeFormat, clock)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+	switch zone {
+	case "", "UTC", "GMT":
+		return t, true, true
+	default:
+		return time.Time{}, false, true
+	}
+}
+
+func postgresLevel(severity string) (ecslogs.Level, bool) {
+	switch {
+	case strings.HasPrefix(severity, "DEBUG"):
+		return ecslogs.DEBUG, true
+	case severity == "LOG", severity == "INFO":
+		return ecslogs.INFO, true
+	case severity == "NOTICE":
+		return ecslogs.NOTICE, true
+	case severity == "WARNING":
+		return ecslogs.WARN, true
+	case severity == "ERROR":
+		return ecslogs.ERROR, true
+	case severity == "FATAL":
+		return ecslogs.CRIT, true
+	case severity == "PANIC":
+		return ecslogs.EMERG, true
+	default:
+		return ecslogs.INFO, false
+	}
+}
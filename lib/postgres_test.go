@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// testPostgresLine is a csvlog entry whose message spans two lines and holds
+// quoted commas
+const testPostgresLine = `2023-11-14 12:00:00.123 UTC,"postgres","app",1234,"10.0.0.1:5555",655355e0.4d2,3,"SELECT",2023-11-14 11:59:00 UTC,3/14,0,ERROR,42P01,"relation ""foo, bar"" does not exist
+second line",,,,,,"SELECT * FROM foo;",15,,"psql","client backend",,0`
+
+func TestParsePostgresCSV(t *testing.T) {
+	e, ok := ParsePostgresCSV("s", testPostgresLine)
+	if !ok {
+		t.Fatal("failed to parse csvlog entry")
+	}
+	if e.Level != ecslogs.ERROR {
+		t.Errorf("Level = %s, want ERROR", e.Level)
+	}
+	if want := "relation \"foo, bar\" does not exist\nsecond line"; e.Message != want {
+		t.Errorf("Message = %q, want %q", e.Message, want)
+	}
+	if e.Data["user_name"] != "postgres" || e.Data["query"] != "SELECT * FROM foo;" {
+		t.Errorf("Data = %v", e.Data)
+	}
+	if want := time.Date(2023, 11, 14, 12, 0, 0, 123e6, time.UTC); !e.Time.Equal(want) {
+		t.Errorf("Time = %s, want %s", e.Time, want)
+	}
+
+	if _, ok := ParsePostgresCSV("s", "not,a,csvlog,entry"); ok {
+		t.Error("parsed a line with too few columns")
+	}
+}
+
+func TestParsePostgresTimeZones(t *testing.T) {
+	tests := []struct {
+		logTime string
+		want    time.Time
+	}{
+		{"2024-01-01 12:00:00.123 GMT", time.Date(2024, 1, 1, 12, 0, 0, 123e6, time.UTC)},
+		{"2024-01-01 12:00:00 +05", time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)},
+		{"2024-01-01 12:00:00.5 -0330", time.Date(2024, 1, 1, 15, 30, 0, 5e8, time.UTC)},
+		// abbreviations can't be resolved, so the time is left unset
+		{"2024-01-01 12:00:00.123 PST", time.Time{}},
+	}
+	for _, test := range tests {
+		line := test.logTime + `,"postgres","app",1234,,,,,,,,LOG,00000,"hello",,,,,,,,,`
+		e, ok := ParsePostgresCSV("s", line)
+		if !ok {
+			t.Errorf("%s: failed to parse", test.logTime)
+			continue
+		}
+		if !e.Time.Equal(test.want) {
+			t.Errorf("%s: Time = %s, want %s", test.logTime, e.Time, test.want)
+		}
+		if test.want.IsZero() && e.Data["log_time"] != test.logTime {
+			t.Errorf("%s: log_time = %v, want it kept in Data", test.logTime, e.Data["log_time"])
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ringHeaderSize is the space reserved at the start of a ring file for its
+// header line
+const ringHeaderSize = 128
+
+// ringHeader describes the layout and position of a ring file
+type ringHeader struct {
+	Slots    int `json:"slots"`
+	SlotSize int `json:"slot_size"`
+	Next     int `json:"next"`
+	Count    int `json:"count"`
+}
+
+// RingFileSink keeps the most recent events in a fixed size file, so that the
+// latest events survive a crash.  The file holds a header line followed by a
+// fixed number of slots, each holding one event as a JSON line padded to the
+// slot size.  Once every slot is full, new events overwrite the oldest.
+type RingFileSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	header ringHeader
+}
+
+// NewRingFileSink opens the ring file at path, holding up to slots events of at
+// most slotSize bytes each once encoded.  An existing ring file with the same
+// layout is appended to, otherwise the file is replaced.
+func NewRingFileSink(path string, slots int, slotSize int) (*RingFileSink, error) {
+	if slots < 1 || slotSize < 2 {
+		return nil, fmt.Errorf("Invalid ring file layout: %d slots of %d bytes", slots, slotSize)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &RingFileSink{file: file}
+	if header, err := readRingHeader(file); err == nil && header.Slots == slots && header.SlotSize == slotSize {
+		sink.header = header
+		return sink, nil
+	}
+
+	sink.header = ringHeader{Slots: slots, SlotSize: slotSize}
+	if err := file.Truncate(int64(ringHeaderSize + slots*slotSize)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := sink.writeHeader(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return sink, nil
+}
+
+// Emit writes the event into the next slot, overwriting the oldest event once
+// the ring is full
+func (s *RingFileSink) Emit(ctx context.Context, e Event) error {
+	line, err := json.Marshal(newJSONLEvent(e))
+	if err != nil {
+		return err
+	}
+	if len(line)+1 > s.header.SlotSize {
+		return fmt.Errorf("Event %s is too large for a %d byte ring slot", e.ID, s.header.SlotSize)
+	}
+
+	slot := bytes.Repeat([]byte(" "), s.header.SlotSize)
+	copy(slot, line)
+	slot[len(slot)-1] = '\n'
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset := int64(ringHeaderSize + s.header.Next*s.header.SlotSize)
+	if _, err := s.file.WriteAt(slot, offset); err != nil {
+		return err
+	}
+
+	s.header.Next = (s.header.Next + 1) % s.header.Slots
+	if s.header.Count < s.header.Slots {
+		s.header.Count++
+	}
+	return s.writeHeader()
+}
+
+// Close syncs and closes the ring file
+func (s *RingFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+func (s *RingFileSink) writeHeader() error {
+	line, err := json.Marshal(s.header)
+	if err != nil {
+		return err
+	}
+	header := bytes.Repeat([]byte(" "), ringHeaderSize)
+	copy(header, line)
+	header[len(header)-1] = '\n'
+
+	_, err = s.file.WriteAt(header, 0)
+	return err
+}
+
+func readRingHeader(file *os.File) (ringHeader, error) {
+	buf := make([]byte, ringHeaderSize)
+	if _, err := file.ReadAt(buf, 0); err != nil {
+		return ringHeader{}, err
+	}
+
+	var header ringHeader
+	if err := json.Unmarshal(bytes.TrimSpace(buf), &header); err != nil {
+		return ringHeader{}, err
+	}
+	if header.Slots < 1 || header.SlotSize < 2 || header.Next < 0 || header.Next >= header.Slots ||
+		header.Count < 0 || header.Count > header.Slots {
+		return ringHeader{}, fmt.Errorf("Invalid ring file header")
+	}
+	return header, nil
+}
+
+// ReadRingFile reads the events held in a ring file written by a RingFileSink,
+// oldest first
+func ReadRingFile(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	header, err := readRingHeader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	first := 0
+	if header.Count == header.Slots {
+		first = header.Next
+	}
+
+	events := make([]Event, 0, header.Count)
+	slot := make([]byte, header.SlotSize)
+	for ix := 0; ix < header.Count; ix++ {
+		offset := int64(ringHeaderSize + ((first+ix)%header.Slots)*header.SlotSize)
+		if _, err := file.ReadAt(slot, offset); err != nil {
+			return events, err
+		}
+		event, err := decodeJSONLEvent(bytes.TrimSpace(slot))
+		if err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,87 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRingFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	ringIDs := func() []string {
+		events, err := ReadRingFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, len(events))
+		for ix, e := range events {
+			ids[ix] = e.ID
+		}
+		return ids
+	}
+	emit := func(sink *RingFileSink, from, to int) {
+		for ix := from; ix < to; ix++ {
+			e := NewEvent(testCWEvent(fmt.Sprint(ix), "s", fmt.Sprintf("event %d", ix), int64(ix)), "g")
+			if err := sink.Emit(context.Background(), e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	sink, err := NewRingFileSink(path, 3, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emit(sink, 0, 2)
+	if got := fmt.Sprint(ringIDs()); got != "[0 1]" {
+		t.Errorf("Expected [0 1] before the ring is full, got %s", got)
+	}
+	emit(sink, 2, 5)
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(ringIDs()); got != "[2 3 4]" {
+		t.Errorf("Expected the oldest events to be overwritten, leaving [2 3 4], got %s", got)
+	}
+
+	// reopening with the same layout carries on from where it left off
+	sink, err = NewRingFileSink(path, 3, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emit(sink, 5, 6)
+	sink.Close()
+	if got := fmt.Sprint(ringIDs()); got != "[3 4 5]" {
+		t.Errorf("Expected [3 4 5] after reopening, got %s", got)
+	}
+
+	sink, err = NewRingFileSink(path, 3, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Emit(context.Background(), NewEvent(testCWEvent("big", "s", "too large for the slot", 1), "g")); err == nil {
+		t.Error("Expected an error for an event larger than a slot")
+	}
+	sink.Close()
+
+	// a corrupt header is an error rather than a crash
+	for _, header := range []ringHeader{
+		{Slots: 3, SlotSize: 32, Next: 0, Count: -1},
+		{Slots: 3, SlotSize: 32, Next: -1, Count: 1},
+		{Slots: 3, SlotSize: 32, Next: 0, Count: 4},
+	} {
+		corrupt := &RingFileSink{header: header}
+		if corrupt.file, err = os.OpenFile(path, os.O_RDWR, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := corrupt.writeHeader(); err != nil {
+			t.Fatal(err)
+		}
+		corrupt.file.Close()
+		if _, err := ReadRingFile(path); err == nil {
+			t.Errorf("Expected an error reading a ring file with header %+v", header)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FieldType is the expected type of a data field
+type FieldType int
+
+// Data field types that can be checked by ValidateSchema
+const (
+	StringField FieldType = iota
+	IntField
+	FloatField
+	BoolField
+)
+
+func (t FieldType) String() string {
+	switch t {
+	case StringField:
+		return "string"
+	case IntField:
+		return "int"
+	case FloatField:
+		return "float"
+	case BoolField:
+		return "bool"
+	default:
+		return fmt.Sprintf("FieldType(%d)", int(t))
+	}
+}
+
+// Schema maps the (possibly dotted) keys of required data fields to their
+// expected types
+type Schema map[string]FieldType
+
+// ValidationError describes a data field of an event which doesn't conform to
+// a schema
+type ValidationError struct {
+	EventID string
+	Field   string
+	// Missing is set if the field is absent, rather than of the wrong type
+	Missing  bool
+	Expected FieldType
+}
+
+func (v ValidationError) Error() string {
+	if v.Missing {
+		return fmt.Sprintf("event %s: missing field '%s'", v.EventID, v.Field)
+	}
+	return fmt.Sprintf("event %s: field '%s' is not of type %s", v.EventID, v.Field, v.Expected)
+}
+
+// ValidateSchema checks that every event has each of the schema's fields, with
+// the expected type, and returns an error for each field that doesn't.  Ints
+// also satisfy float fields.  Errors are ordered by event, then field name.
+func ValidateSchema(events []Event, schema Schema) []ValidationError {
+	fields := make([]string, 0, len(schema))
+	for field := range schema {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var errs []ValidationError
+	for _, e := range events {
+		for _, field := range fields {
+			expected := schema[field]
+			value, ok := e.DataValue(field)
+			if !ok {
+				errs = append(errs, ValidationError{
+					EventID:  e.ID,
+					Field:    field,
+					Missing:  true,
+					Expected: expected,
+				})
+				continue
+			}
+			if !hasFieldType(value, expected) {
+				errs = append(errs, ValidationError{
+					EventID:  e.ID,
+					Field:    field,
+					Expected: expected,
+				})
+			}
+		}
+	}
+	return errs
+}
+
+func hasFieldType(value interface{}, t FieldType) bool {
+	switch t {
+	case StringField:
+		_, ok := value.(string)
+		return ok
+	case BoolField:
+		_, ok := value.(bool)
+		return ok
+	case IntField:
+		if _, ok := value.(string); ok {
+			return false
+		}
+		_, ok := dataInt(value)
+		return ok
+	case FloatField:
+		if _, ok := value.(string); ok {
+			return false
+		}
+		_, ok := dataFloat(value)
+		return ok
+	default:
+		return false
+	}
+}
@@ -0,0 +1,41 @@
+package lib
+
+import "testing"
+
+func TestValidateSchema(t *testing.T) {
+	events := []Event{
+		NewEvent(testCWEvent("ok", "s", `{"msg":"a","status":200,"latency":1,"cached":true,"request":{"path":"/"}}`, 1), "g"),
+		NewEvent(testCWEvent("mismatch", "s", `{"msg":"b","status":"200","latency":1.5,"cached":"yes","request":{"path":"/"}}`, 1), "g"),
+		NewEvent(testCWEvent("missing", "s", `{"msg":"c","status":200.5,"latency":1}`, 1), "g"),
+	}
+	schema := Schema{
+		"status":       IntField,
+		"latency":      FloatField,
+		"cached":       BoolField,
+		"request.path": StringField,
+	}
+
+	want := []ValidationError{
+		{EventID: "mismatch", Field: "cached", Expected: BoolField},
+		{EventID: "mismatch", Field: "status", Expected: IntField},
+		{EventID: "missing", Field: "cached", Missing: true, Expected: BoolField},
+		{EventID: "missing", Field: "request.path", Missing: true, Expected: StringField},
+		{EventID: "missing", Field: "status", Expected: IntField},
+	}
+	errs := ValidateSchema(events, schema)
+	if len(errs) != len(want) {
+		t.Fatalf("Expected %d errors, got %v", len(want), errs)
+	}
+	for ix, err := range errs {
+		if err != want[ix] {
+			t.Errorf("Error %d = %v, want %v", ix, err, want[ix])
+		}
+	}
+
+	if msg := want[0].Error(); msg != "event mismatch: field 'cached' is not of type bool" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	if msg := want[2].Error(); msg != "event missing: missing field 'cached'" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+}
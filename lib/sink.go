@@ -0,0 +1,156 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+const (
+	// DefaultHTTPBatchSize is the number of events an HTTPSink posts at once
+	DefaultHTTPBatchSize = 100
+)
+
+// Sink is a destination for log events, such as a file or a remote service
+type Sink interface {
+	// Emit sends an event to the sink.  Sinks may buffer events, so an event
+	// isn't guaranteed to be delivered until Close returns.
+	Emit(ctx context.Context, e Event) error
+	// Close flushes any buffered events and releases the sink's resources
+	Close() error
+}
+
+// EmitEvents streams events matching the reader's params to sink, returning
+// once all events are read or an error occurs.  The sink is not closed.
+func (c *CloudwatchLogsReader) EmitEvents(ctx context.Context, follow bool, sink Sink) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := c.StreamEvents(ctx, follow)
+	for event := range events {
+		if err := sink.Emit(ctx, event); err != nil {
+			cancel()
+			// drain the channel so the pump can exit
+			for range events {
+			}
+			return err
+		}
+	}
+	return c.Error()
+}
+
+// WriterSink writes events to an io.Writer as JSON lines
+type WriterSink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewWriterSink returns a sink which writes events to w as JSON lines.  If w
+// is an io.Closer, it is closed along with the sink.
+func NewWriterSink(w io.Writer) *WriterSink {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &WriterSink{
+		w:   w,
+		enc: enc,
+	}
+}
+
+// Emit writes the event to the underlying writer
+func (s *WriterSink) Emit(ctx context.Context, e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(newJSONLEvent(e))
+}
+
+// Close closes the underlying writer, if it is an io.Closer
+func (s *WriterSink) Close() error {
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// HTTPSink posts batches of events to a URL as a JSON array
+type HTTPSink struct {
+	// Client is the client used to post events, http.DefaultClient by default
+	Client *http.Client
+
+	mu        sync.Mutex
+	url       string
+	batchSize int
+	batch     []jsonlEvent
+}
+
+// NewHTTPSink returns a sink which posts events to url in batches of up to
+// batchSize events, or DefaultHTTPBatchSize if batchSize is not positive
+func NewHTTPSink(url string, batchSize int) *HTTPSink {
+	if batchSize <= 0 {
+		batchSize = DefaultHTTPBatchSize
+	}
+	return &HTTPSink{
+		Client:    http.DefaultClient,
+		url:       url,
+		batchSize: batchSize,
+	}
+}
+
+// Emit adds the event to the current batch, posting the batch once it is full
+func (s *HTTPSink) Emit(ctx context.Context, e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batch = append(s.batch, newJSONLEvent(e))
+	if len(s.batch) < s.batchSize {
+		return nil
+	}
+	return s.flush(ctx)
+}
+
+// Close posts any events in the current batch
+func (s *HTTPSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush(context.Background())
+}
+
+func (s *HTTPSink) flush(ctx context.Context) error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(s.batch)
+	if err != nil {
+		return err
+	}
+	s.batch = s.batch[:0]
+
+	return postJSON(ctx, s.Client, s.url, body)
+}
+
+// postJSON posts body to url, returning an error for non-2xx responses
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Failed to post events to %s: %s", url, resp.Status)
+	}
+	return nil
+}
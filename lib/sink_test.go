@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordingWriter records what's written to it, and whether it was closed
+type recordingWriter struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *recordingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func emitAll(t *testing.T, sink Sink, events []Event) {
+	t.Helper()
+	for _, e := range events {
+		if err := sink.Emit(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testSinkEvents() []Event {
+	return []Event{
+		NewEvent(testCWEvent("1", "s", `{"msg":"first","status":200}`, 1700000000000), "g"),
+		NewEvent(testCWEvent("2", "s", "second", 1700000001000), "g"),
+		NewEvent(testCWEvent("3", "s", "third", 1700000002000), "g"),
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	w := &recordingWriter{}
+	sink := NewWriterSink(w)
+	emitAll(t, sink, testSinkEvents())
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !w.closed {
+		t.Error("Expected the writer to be closed with the sink")
+	}
+
+	events, err := ReadJSONL(&w.Buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || events[0].Message != "first" || events[2].Message != "third" {
+		t.Fatalf("Unexpected events written %v", events)
+	}
+	if status, _ := events[0].DataValue("status"); dataString(status) != "200" {
+		t.Errorf("Expected status 200, got %v", status)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var batches [][]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected content type '%s'", r.Header.Get("Content-Type"))
+		}
+		var batch []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Error(err)
+		}
+		batches = append(batches, batch)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, 2)
+	emitAll(t, sink, testSinkEvents())
+	if len(batches) != 1 {
+		t.Fatalf("Expected a batch to be posted once full, got %d", len(batches))
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("Expected batches of 2 and 1 events, got %v", batches)
+	}
+	if batches[0][0]["msg"] != "first" || batches[1][0]["id"] != "3" {
+		t.Errorf("Unexpected batches %v", batches)
+	}
+}
+
+func TestHTTPSinkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, 1)
+	err := sink.Emit(context.Background(), testSinkEvents()[0])
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected an error for the 503 response, got %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package lib
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+var (
+	// SortRunSize is the maximum number of events FetchSortedToWriter holds in
+	// memory at once
+	SortRunSize = 10000
+
+	// MaxMergeFanIn is the maximum number of sorted runs FetchSortedToWriter
+	// merges at once, and so the number of temporary files it has open
+	MaxMergeFanIn = 64
+)
+
+// SetSortRunSize sets the maximum number of events held in memory while
+// sorting fetched events
+func SetSortRunSize(size int) {
+	SortRunSize = size
+}
+
+// SetMaxMergeFanIn sets the maximum number of sorted runs merged at once.
+// With more runs than this, they're merged in several passes.
+func SetMaxMergeFanIn(fanIn int) {
+	MaxMergeFanIn = fanIn
+}
+
+// FetchSortedToWriter fetches all events matching the reader's params and
+// writes them to w as JSON lines, sorted by creation time.  To keep memory use
+// bounded, events are sorted in runs of at most SortRunSize events, which are
+// written to temporary files and then merged, at most MaxMergeFanIn at a time.
+func (c *CloudwatchLogsReader) FetchSortedToWriter(ctx context.Context, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	runSize := SortRunSize
+	if runSize < 1 {
+		runSize = 1
+	}
+
+	var runs []string
+	defer func() {
+		removeRuns(runs)
+	}()
+
+	events := c.StreamEvents(ctx, false)
+	batch := make([]Event, 0, runSize)
+	for event := range events {
+		batch = append(batch, event)
+		if len(batch) < runSize {
+			continue
+		}
+
+		run, err := writeSortedRun(batch)
+		if err != nil {
+			cancel()
+			for range events {
+			}
+			return err
+		}
+		runs = append(runs, run)
+		batch = batch[:0]
+	}
+	if err := c.Error(); err != nil {
+		return err
+	}
+
+	// Everything fit in a single run, so skip the merge
+	if len(runs) == 0 {
+		sortForOutput(batch)
+		return WriteJSONL(w, batch)
+	}
+
+	if len(batch) > 0 {
+		run, err := writeSortedRun(batch)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+	}
+
+	merge := runs
+	runs = nil
+	return mergeAllRuns(merge, MaxMergeFanIn, w)
+}
+
+// sortForOutput sorts events by creation time, breaking ties by ID so that the
+// order is the same regardless of how events were split into runs
+func sortForOutput(events []Event) {
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].CreationTime.Equal(events[j].CreationTime) {
+			return events[i].CreationTime.Before(events[j].CreationTime)
+		}
+		return events[i].ID < events[j].ID
+	})
+}
+
+// writeSortedRun sorts events and writes them to a temporary file, returning
+// its name
+func writeSortedRun(events []Event) (string, error) {
+	sortForOutput(events)
+	return writeRun(func(w io.Writer) error {
+		return WriteJSONL(w, events)
+	})
+}
+
+// writeRun writes a run to a new temporary file with write, returning its
+// name.  The file is closed, and removed if writing it fails.
+func writeRun(write func(w io.Writer) error) (string, error) {
+	run, err := os.CreateTemp("", "cwlogs-run-*.jsonl")
+	if err != nil {
+		return "", err
+	}
+
+	buf := bufio.NewWriter(run)
+	err = write(buf)
+	if err == nil {
+		err = buf.Flush()
+	}
+	if closeErr := run.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(run.Name())
+		return "", err
+	}
+	return run.Name(), nil
+}
+
+func removeRuns(runs []string) {
+	for _, run := range runs {
+		os.Remove(run)
+	}
+}
+
+// mergeAllRuns merges the sorted run files into w, merging at most fanIn of
+// them at a time into new runs until few enough remain to merge into w.  The
+// run files, and any created while merging, are removed.
+func mergeAllRuns(runs []string, fanIn int, w io.Writer) error {
+	if fanIn < 2 {
+		fanIn = 2
+	}
+	defer func() {
+		removeRuns(runs)
+	}()
+
+	// Merged runs go on the end, so each pass merges runs of similar size
+	for len(runs) > fanIn {
+		merged, err := writeRun(func(w io.Writer) error {
+			return mergeRuns(runs[:fanIn], w)
+		})
+		if err != nil {
+			return err
+		}
+		removeRuns(runs[:fanIn])
+		runs = append(runs[fanIn:], merged)
+	}
+	return mergeRuns(runs, w)
+}
+
+// runHead is the next unwritten line of a sorted run
+type runHead struct {
+	scanner      *bufio.Scanner
+	line         []byte
+	creationTime time.Time
+	id           string
+}
+
+// next advances to the run's next line, returning false once it is exhausted
+func (r *runHead) next() (bool, error) {
+	if !r.scanner.Scan() {
+		return false, r.scanner.Err()
+	}
+	r.line = append(r.line[:0], r.scanner.Bytes()...)
+
+	var key struct {
+		ID           string     `json:"id"`
+		CreationTime outputTime `json:"creation_time"`
+	}
+	if err := json.Unmarshal(r.line, &key); err != nil {
+		return false, err
+	}
+	r.creationTime = key.CreationTime.Time
+	r.id = key.ID
+	return true, nil
+}
+
+type runHeap []*runHead
+
+func (h runHeap) Len() int      { return len(h) }
+func (h runHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h runHeap) Less(i, j int) bool {
+	if !h[i].creationTime.Equal(h[j].creationTime) {
+		return h[i].creationTime.Before(h[j].creationTime)
+	}
+	return h[i].id < h[j].id
+}
+func (h *runHeap) Push(x interface{}) { *h = append(*h, x.(*runHead)) }
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	head := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return head
+}
+
+// mergeRuns merges the sorted run files into w, copying each line as-is
+func mergeRuns(runs []string, w io.Writer) error {
+	h := make(runHeap, 0, len(runs))
+	for _, name := range runs {
+		run, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer run.Close()
+
+		scanner := bufio.NewScanner(run)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		head := &runHead{scanner: scanner}
+		ok, err := head.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			h = append(h, head)
+		}
+	}
+	heap.Init(&h)
+
+	out := bufio.NewWriter(w)
+	for h.Len() > 0 {
+		head := h[0]
+		if _, err := out.Write(head.line); err != nil {
+			return err
+		}
+		if err := out.WriteByte('\n'); err != nil {
+			return err
+		}
+
+		ok, err := head.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return out.Flush()
+}
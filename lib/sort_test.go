@@ -0,0 +1,267 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/hashicorp/golang-lru"
+)
+
+func TestMergeAllRuns(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+
+	start := time.Date(2023, 11, 14, 12, 0, 0, 0, time.UTC)
+	random := rand.New(rand.NewSource(1))
+	var runs []string
+	total := 0
+	// more runs than the fan-in, so they're merged in several passes
+	for page := 0; page < 11; page++ {
+		events := make([]Event, 20)
+		for ix := range events {
+			events[ix] = Event{
+				ID:           fmt.Sprintf("%d-%d", page, ix),
+				CreationTime: start.Add(time.Duration(random.Intn(100)) * time.Second),
+			}
+		}
+		run, err := writeSortedRun(events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, run)
+		total += len(events)
+	}
+
+	var out bytes.Buffer
+	if err := mergeAllRuns(runs, 3, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := ReadJSONL(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != total {
+		t.Fatalf("Expected %d events, got %d", total, len(merged))
+	}
+	for ix := 1; ix < len(merged); ix++ {
+		prev, e := merged[ix-1], merged[ix]
+		if e.CreationTime.Before(prev.CreationTime) || (e.CreationTime.Equal(prev.CreationTime) && e.ID < prev.ID) {
+			t.Fatalf("Event %d (%s at %s) sorted after %s at %s", ix, e.ID, e.CreationTime, prev.ID, prev.CreationTime)
+		}
+	}
+
+	left, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("Expected run files to be removed, found %d", len(left))
+	}
+}
+
+// testFilterLogEventsServer serves pages of FilterLogEvents results, chained
+// by NextToken, calling onPage before serving each one
+func testFilterLogEventsServer(t *testing.T, pages [][]*cloudwatchlogs.FilteredLogEvent, onPage func(page int)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "Logs_20140328.FilterLogEvents" {
+			t.Errorf("Unexpected call %s", target)
+			http.Error(w, "unexpected call", http.StatusBadRequest)
+			return
+		}
+		var input cloudwatchlogs.FilterLogEventsInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Error(err)
+		}
+
+		page := 0
+		if input.NextToken != nil {
+			page, _ = strconv.Atoi(*input.NextToken)
+		}
+		onPage(page)
+
+		output := cloudwatchlogs.FilterLogEventsOutput{Events: pages[page]}
+		if page+1 < len(pages) {
+			output.NextToken = aws.String(strconv.Itoa(page + 1))
+		}
+		writeAWSJSON(t, w, &output)
+	}))
+}
+
+func TestFetchSortedToWriter(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	defer SetSortRunSize(SortRunSize)
+	defer SetMaxMergeFanIn(MaxMergeFanIn)
+	SetSortRunSize(4)
+	SetMaxMergeFanIn(2)
+
+	// creation times are shuffled across pages, as FilterLogEvents only
+	// orders events within each stream
+	start := time.Date(2023, 11, 14, 12, 0, 0, 0, time.UTC)
+	random := rand.New(rand.NewSource(1))
+	var pages [][]*cloudwatchlogs.FilteredLogEvent
+	total := 0
+	for page := 0; page < 5; page++ {
+		var events []*cloudwatchlogs.FilteredLogEvent
+		for ix := 0; ix < 7; ix++ {
+			e := testCWEvent(fmt.Sprintf("%d-%d", page, ix), "s", "m", start.Add(time.Duration(random.Intn(60))*time.Second).UnixMilli())
+			events = append(events, &e)
+			total++
+		}
+		pages = append(pages, events)
+	}
+
+	// events are read as each page is fetched, so by the last page the
+	// earlier ones have been spilled into runs
+	spilled := 0
+	server := testFilterLogEventsServer(t, pages, func(page int) {
+		if page != len(pages)-1 {
+			return
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Error(err)
+		}
+		spilled = len(entries)
+	})
+	defer server.Close()
+
+	c := testCloudwatchLogsReader(t, server.URL, start)
+	var out bytes.Buffer
+	if err := c.FetchSortedToWriter(context.Background(), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if spilled < 2 {
+		t.Errorf("Expected more than one run to be spilled, found %d", spilled)
+	}
+	assertSortedOutput(t, &out, total)
+
+	if left, err := os.ReadDir(dir); err != nil || len(left) != 0 {
+		t.Errorf("Expected run files to be removed, found %d (%v)", len(left), err)
+	}
+}
+
+func TestFetchSortedToWriterSingleRun(t *testing.T) {
+	start := time.Date(2023, 11, 14, 12, 0, 0, 0, time.UTC)
+	var events []*cloudwatchlogs.FilteredLogEvent
+	for ix, offset := range []int{3, 1, 2} {
+		e := testCWEvent(fmt.Sprint(ix), "s", "m", start.Add(time.Duration(offset)*time.Second).UnixMilli())
+		events = append(events, &e)
+	}
+	server := testFilterLogEventsServer(t, [][]*cloudwatchlogs.FilteredLogEvent{events}, func(int) {})
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := testCloudwatchLogsReader(t, server.URL, start).FetchSortedToWriter(context.Background(), &out); err != nil {
+		t.Fatal(err)
+	}
+	assertSortedOutput(t, &out, len(events))
+}
+
+func TestFetchSortedToWriterError(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	defer SetSortRunSize(SortRunSize)
+	SetSortRunSize(2)
+
+	start := time.Date(2023, 11, 14, 12, 0, 0, 0, time.UTC)
+	var events []*cloudwatchlogs.FilteredLogEvent
+	for ix := 0; ix < 5; ix++ {
+		e := testCWEvent(fmt.Sprint(ix), "s", "m", start.UnixMilli())
+		events = append(events, &e)
+	}
+	served := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first page spills runs, then the next page fails
+		if served {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"InvalidParameterException","message":"bad token"}`))
+			return
+		}
+		served = true
+		writeAWSJSON(t, w, &cloudwatchlogs.FilterLogEventsOutput{Events: events, NextToken: aws.String("1")})
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := testCloudwatchLogsReader(t, server.URL, start).FetchSortedToWriter(context.Background(), &out); err == nil {
+		t.Error("Expected the failed page to be an error")
+	}
+	if left, err := os.ReadDir(dir); err != nil || len(left) != 0 {
+		t.Errorf("Expected run files to be removed, found %d (%v)", len(left), err)
+	}
+}
+
+// writeAWSJSON writes an API response using the service's field names
+func writeAWSJSON(t *testing.T, w http.ResponseWriter, output interface{}) {
+	body, err := jsonutil.BuildJSON(output)
+	if err != nil {
+		t.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.Write(body)
+}
+
+// testCloudwatchLogsReader returns a reader of events since start from the
+// CloudWatch Logs API at endpoint
+func testCloudwatchLogsReader(t *testing.T, endpoint string, start time.Time) *CloudwatchLogsReader {
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:    aws.String(endpoint),
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := lru.New(MaxEventsPerCall)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CloudwatchLogsReader{
+		logGroupName: "g",
+		svc:          cloudwatchlogs.New(sess),
+		eventCache:   cache,
+		start:        start,
+		end:          start.Add(time.Hour),
+	}
+}
+
+// assertSortedOutput checks that r holds count JSON lines, sorted by creation
+// time and then ID
+func assertSortedOutput(t *testing.T, r io.Reader, count int) {
+	t.Helper()
+	events, err := ReadJSONL(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != count {
+		t.Fatalf("Expected %d events, got %d", count, len(events))
+	}
+	for ix := 1; ix < len(events); ix++ {
+		prev, e := events[ix-1], events[ix]
+		if e.CreationTime.Before(prev.CreationTime) || (e.CreationTime.Equal(prev.CreationTime) && e.ID < prev.ID) {
+			t.Fatalf("Event %d (%s at %s) sorted after %s at %s", ix, e.ID, e.CreationTime, prev.ID, prev.CreationTime)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	// registers the pure Go sqlite driver, so builds don't need cgo
+	_ "modernc.org/sqlite"
+)
+
+// sqliteTimeFormat is a fixed width UTC timestamp format, so that times stored
+// as text sort correctly and work with SQLite's date and time functions
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS events (
+		id TEXT PRIMARY KEY,
+		log_group TEXT NOT NULL,
+		stream TEXT NOT NULL,
+		level TEXT NOT NULL,
+		time TEXT NOT NULL,
+		creation_time TEXT NOT NULL,
+		ingest_time TEXT NOT NULL,
+		message TEXT NOT NULL,
+		data TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS events_time ON events (time)`,
+	`CREATE INDEX IF NOT EXISTS events_level ON events (level)`,
+	`CREATE INDEX IF NOT EXISTS events_stream ON events (stream)`,
+}
+
+// WriteSQLite writes events to an "events" table in the SQLite database at
+// path, creating the database and table if needed.  Data fields are stored as
+// a JSON object in the data column, which can be queried with SQLite's JSON
+// functions.  Events are inserted in a single transaction, replacing any
+// existing events with the same ID.
+func WriteSQLite(path string, events []Event) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for _, stmt := range sqliteSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	insert, err := tx.Prepare(`INSERT OR REPLACE INTO events
+		(id, log_group, stream, level, time, creation_time, ingest_time, message, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	for _, e := range events {
+		e = e.Parsed()
+		var data interface{}
+		if len(e.Data) > 0 {
+			encoded, err := json.Marshal(e.Data)
+			if err != nil {
+				return err
+			}
+			data = string(encoded)
+		}
+
+		if _, err := insert.Exec(
+			e.ID,
+			e.Group,
+			e.Stream,
+			e.Level.String(),
+			formatSQLiteTime(e.Time),
+			formatSQLiteTime(e.CreationTime),
+			formatSQLiteTime(e.IngestTime),
+			e.Message,
+			data,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
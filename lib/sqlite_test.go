@@ -0,0 +1,54 @@
+package lib
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.db")
+	created := time.Date(2023, 11, 14, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{ID: "1", Group: "g", Stream: "a", CreationTime: created, SlogEvent: SlogEvent{Message: "first", Data: map[string]interface{}{"status": 500}}},
+		{ID: "2", Group: "g", Stream: "b", CreationTime: created.Add(time.Second), SlogEvent: SlogEvent{Message: "second"}},
+	}
+	if err := WriteSQLite(path, events); err != nil {
+		t.Fatal(err)
+	}
+	// writing the same events again replaces them
+	if err := WriteSQLite(path, events); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM events`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 events, got %d", count)
+	}
+
+	var id, message string
+	if err := db.QueryRow(`SELECT id, message FROM events WHERE json_extract(data, '$.status') = 500`).Scan(&id, &message); err != nil {
+		t.Fatal(err)
+	}
+	if id != "1" || message != "first" {
+		t.Errorf("Expected event 1 'first', got %s '%s'", id, message)
+	}
+
+	var stream string
+	if err := db.QueryRow(`SELECT stream FROM events WHERE creation_time > ?`, formatSQLiteTime(created)).Scan(&stream); err != nil {
+		t.Fatal(err)
+	}
+	if stream != "b" {
+		t.Errorf("Expected stream b after %s, got %s", created, stream)
+	}
+}
@@ -0,0 +1,267 @@
+package lib
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// ErrorRate returns the fraction of events with a level at least as severe as
+// min, or 0 if there are no events
+func ErrorRate(events []Event, min ecslogs.Level) float64 {
+	if len(events) == 0 {
+		return 0
+	}
+
+	matched := 0
+	for _, e := range events {
+		if AtLeastLevel(e.Parsed().Level, min) {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(events))
+}
+
+// Span describes the window of time covered by a log stream's events
+type Span struct {
+	First time.Time
+	Last  time.Time
+	Count int
+}
+
+// StreamSpans returns the span of each stream's events, keyed by stream name,
+// based on their creation time
+func StreamSpans(events []Event) map[string]Span {
+	spans := map[string]Span{}
+	for _, e := range events {
+		e = e.Parsed()
+		span, ok := spans[e.Stream]
+		if !ok || e.CreationTime.Before(span.First) {
+			span.First = e.CreationTime
+		}
+		if !ok || e.CreationTime.After(span.Last) {
+			span.Last = e.CreationTime
+		}
+		span.Count++
+		spans[e.Stream] = span
+	}
+	return spans
+}
+
+// SilentStreams returns the names of the streams with events in previous but
+// none in current, sorted, such as instances which have stopped logging
+func SilentStreams(previous, current []Event) []string {
+	active := map[string]bool{}
+	for _, e := range current {
+		active[e.Parsed().Stream] = true
+	}
+
+	silent := map[string]bool{}
+	for _, e := range previous {
+		if stream := e.Parsed().Stream; !active[stream] {
+			silent[stream] = true
+		}
+	}
+
+	names := make([]string, 0, len(silent))
+	for name := range silent {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Spike is a time bucket with an unusually high number of events
+type Spike struct {
+	Start time.Time
+	Count int
+	// StdDevs is how many standard deviations Count is above the mean
+	StdDevs float64
+}
+
+// DetectSpikes groups events by creation time into buckets of the given size,
+// and returns the buckets whose count is more than stddevThreshold standard
+// deviations above the mean count.  Buckets without any events between the
+// first and last event count towards the baseline.
+func DetectSpikes(events []Event, bucket time.Duration, stddevThreshold float64) []Spike {
+	if len(events) == 0 || bucket <= 0 {
+		return nil
+	}
+
+	first, last := events[0].CreationTime, events[0].CreationTime
+	for _, e := range events {
+		if e.CreationTime.Before(first) {
+			first = e.CreationTime
+		}
+		if e.CreationTime.After(last) {
+			last = e.CreationTime
+		}
+	}
+	first = first.Truncate(bucket)
+
+	// Only buckets with events are stored, since there can be far more
+	// buckets than events when they're small
+	counts := map[int64]int{}
+	for _, e := range events {
+		counts[int64(e.CreationTime.Sub(first)/bucket)]++
+	}
+	buckets := float64(int64(last.Sub(first)/bucket) + 1)
+
+	mean := float64(len(events)) / buckets
+	// empty buckets each differ from the mean by the mean
+	variance := (buckets - float64(len(counts))) * mean * mean
+	for _, count := range counts {
+		variance += (float64(count) - mean) * (float64(count) - mean)
+	}
+	stddev := math.Sqrt(variance / buckets)
+	if stddev == 0 {
+		return nil
+	}
+
+	var spikes []Spike
+	for ix, count := range counts {
+		if devs := (float64(count) - mean) / stddev; devs > stddevThreshold {
+			spikes = append(spikes, Spike{
+				Start:   first.Add(time.Duration(ix) * bucket),
+				Count:   count,
+				StdDevs: devs,
+			})
+		}
+	}
+	sort.Slice(spikes, func(i, j int) bool { return spikes[i].Start.Before(spikes[j].Start) })
+	return spikes
+}
+
+// StructuredRatio returns the fraction of events whose messages were
+// structured, or 0 if there are no events
+func StructuredRatio(events []Event) float64 {
+	if len(events) == 0 {
+		return 0
+	}
+
+	structured := 0
+	for _, e := range events {
+		if e.IsStructured() {
+			structured++
+		}
+	}
+	return float64(structured) / float64(len(events))
+}
+
+// MovingAverage returns the simple moving average of the numeric data field
+// key over each run of window consecutive values, with events ordered by
+// creation time.  Events where the field is missing or not a number are
+// skipped.  The result has one average per full window, so it is empty if
+// there are fewer than window values.
+func MovingAverage(events []Event, key string, window int) []float64 {
+	if window < 1 {
+		return nil
+	}
+
+	sorted := make([]Event, len(events))
+	copy(sorted, events)
+	sort.Stable(ByCreationTime(sorted))
+
+	var values []float64
+	for _, e := range sorted {
+		if v, ok := e.DataValue(key); ok {
+			if f, ok := dataFloat(v); ok {
+				values = append(values, f)
+			}
+		}
+	}
+	if len(values) < window {
+		return []float64{}
+	}
+
+	averages := make([]float64, 0, len(values)-window+1)
+	sum := 0.0
+	for ix, v := range values {
+		sum += v
+		if ix >= window {
+			sum -= values[ix-window]
+		}
+		if ix >= window-1 {
+			averages = append(averages, sum/float64(window))
+		}
+	}
+	return averages
+}
+
+// HistogramBucket is the number of events in a range of a histogram.  Lower is
+// inclusive and Upper exclusive, and the overflow bucket has no upper bound.
+type HistogramBucket struct {
+	Lower    time.Duration
+	Upper    time.Duration
+	Overflow bool
+	Count    int
+}
+
+// LatencyHistogram counts events by the duration in their data field key (see
+// Event.Duration) into buckets split at bucketBounds, plus an overflow bucket
+// for durations at or above the largest bound.  The first bucket starts at
+// zero, and takes any negative durations.  Events without a duration in the
+// field aren't counted.
+func LatencyHistogram(events []Event, key string, bucketBounds []time.Duration) []HistogramBucket {
+	bounds := make([]time.Duration, len(bucketBounds))
+	copy(bounds, bucketBounds)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	buckets := make([]HistogramBucket, len(bounds)+1)
+	var lower time.Duration
+	for ix, upper := range bounds {
+		buckets[ix] = HistogramBucket{Lower: lower, Upper: upper}
+		lower = upper
+	}
+	buckets[len(bounds)] = HistogramBucket{Lower: lower, Overflow: true}
+
+	for _, e := range events {
+		d, ok := e.Duration(key)
+		if !ok {
+			continue
+		}
+		ix := sort.Search(len(bounds), func(i int) bool { return d < bounds[i] })
+		buckets[ix].Count++
+	}
+	return buckets
+}
+
+// IngestLagPercentiles returns the given percentiles (between 0 and 100) of
+// the events' ingest lag, keyed by percentile, using the nearest rank method.
+// Events without a creation or ingest time aren't counted, and if no events
+// have both the result is empty.
+func IngestLagPercentiles(events []Event, ps []float64) map[float64]time.Duration {
+	lags := []time.Duration{}
+	for _, e := range events {
+		if unsetTime(e.CreationTime) || unsetTime(e.IngestTime) {
+			continue
+		}
+		lags = append(lags, e.IngestLag())
+	}
+
+	percentiles := map[float64]time.Duration{}
+	if len(lags) == 0 {
+		return percentiles
+	}
+	sort.Slice(lags, func(i, j int) bool { return lags[i] < lags[j] })
+
+	for _, p := range ps {
+		rank := int(math.Ceil(p / 100 * float64(len(lags))))
+		if rank < 1 {
+			rank = 1
+		}
+		if rank > len(lags) {
+			rank = len(lags)
+		}
+		percentiles[p] = lags[rank-1]
+	}
+	return percentiles
+}
+
+// unsetTime reports whether t is the zero time, or the Unix epoch that
+// ParseAWSTimestamp gives for a missing timestamp
+func unsetTime(t time.Time) bool {
+	return t.IsZero() || t.Equal(time.Unix(0, 0))
+}
@@ -0,0 +1,230 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func levelEvents(levels ...ecslogs.Level) []Event {
+	events := make([]Event, len(levels))
+	for ix, level := range levels {
+		events[ix] = Event{SlogEvent: SlogEvent{Level: level}}
+	}
+	return events
+}
+
+func TestErrorRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []Event
+		want   float64
+	}{
+		{"no events", nil, 0},
+		{"all errors", levelEvents(ecslogs.ERROR, ecslogs.CRIT, ecslogs.EMERG), 1},
+		{"no errors", levelEvents(ecslogs.INFO, ecslogs.WARN, ecslogs.DEBUG), 0},
+		{"mixed", levelEvents(ecslogs.INFO, ecslogs.ERROR, ecslogs.WARN, ecslogs.ALERT), 0.5},
+	}
+	for _, test := range tests {
+		if got := ErrorRate(test.events, ecslogs.ERROR); got != test.want {
+			t.Errorf("%s: ErrorRate = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDetectSpikes(t *testing.T) {
+	base := time.Unix(60000, 0)
+	var events []Event
+	for minute := 0; minute < 10; minute++ {
+		// a flat baseline of 5 events a minute, with 50 in minute 6
+		n := 5
+		if minute == 6 {
+			n = 50
+		}
+		for i := 0; i < n; i++ {
+			created := base.Add(time.Duration(minute)*time.Minute + time.Duration(i)*time.Second)
+			events = append(events, Event{CreationTime: created})
+		}
+	}
+
+	spikes := DetectSpikes(events, time.Minute, 2)
+	if len(spikes) != 1 {
+		t.Fatalf("found %d spikes, want 1: %+v", len(spikes), spikes)
+	}
+	if want := base.Add(6 * time.Minute); !spikes[0].Start.Equal(want) || spikes[0].Count != 50 {
+		t.Errorf("spike = %+v, want 50 events at %s", spikes[0], want)
+	}
+
+	if spikes := DetectSpikes(events[:5], time.Minute, 2); len(spikes) != 0 {
+		t.Errorf("found spikes %+v in a single bucket", spikes)
+	}
+}
+
+func TestDetectSpikesSmallBuckets(t *testing.T) {
+	base := time.Unix(60000, 0)
+	events := []Event{
+		{CreationTime: base},
+		{CreationTime: base.Add(time.Hour)},
+		{CreationTime: base.Add(time.Hour)},
+	}
+
+	// an hour's worth of nanosecond buckets, most of them empty
+	spikes := DetectSpikes(events, time.Nanosecond, 2)
+	if len(spikes) != 2 || !spikes[1].Start.Equal(base.Add(time.Hour)) || spikes[1].Count != 2 {
+		t.Errorf("spikes = %+v", spikes)
+	}
+}
+
+func TestStreamSpans(t *testing.T) {
+	base := time.Unix(60000, 0)
+	at := func(stream string, seconds int) Event {
+		return Event{Stream: stream, CreationTime: base.Add(time.Duration(seconds) * time.Second)}
+	}
+	// the streams overlap between 10 and 20 seconds, and arrive out of order
+	events := []Event{at("a", 10), at("b", 15), at("a", 0), at("b", 30), at("a", 20), at("b", 10)}
+
+	spans := StreamSpans(events)
+	want := map[string]Span{
+		"a": {First: base, Last: base.Add(20 * time.Second), Count: 3},
+		"b": {First: base.Add(10 * time.Second), Last: base.Add(30 * time.Second), Count: 3},
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("spans = %+v, want %+v", spans, want)
+	}
+	for stream, span := range want {
+		got := spans[stream]
+		if !got.First.Equal(span.First) || !got.Last.Equal(span.Last) || got.Count != span.Count {
+			t.Errorf("span of %s = %+v, want %+v", stream, got, span)
+		}
+	}
+}
+
+func TestStructuredRatio(t *testing.T) {
+	events := []Event{
+		NewEvent(testCWEvent("1", "s", `{"msg":"a"}`, 1), "g"),
+		NewEvent(testCWEvent("2", "s", `plain`, 1), "g"),
+		NewEvent(testCWEvent("3", "s", `{"msg":"b"}`, 1), "g"),
+		NewEvent(testCWEvent("4", "s", `also plain`, 1), "g"),
+	}
+	if got := StructuredRatio(events); got != 0.5 {
+		t.Errorf("StructuredRatio = %v, want 0.5", got)
+	}
+	if got := StructuredRatio(events[1:2]); got != 0 {
+		t.Errorf("StructuredRatio of plain events = %v, want 0", got)
+	}
+	if got := StructuredRatio(nil); got != 0 {
+		t.Errorf("StructuredRatio of no events = %v, want 0", got)
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	base := time.Unix(60000, 0)
+	messages := []string{
+		`{"msg":"a","latency":4}`,
+		`{"msg":"b","latency":"6"}`,
+		`{"msg":"c"}`,
+		`{"msg":"d","latency":"fast"}`,
+		`{"msg":"e","latency":2}`,
+		`{"msg":"f","latency":8}`,
+	}
+	var events []Event
+	// events are given newest first, and averaged oldest first
+	for ix := len(messages) - 1; ix >= 0; ix-- {
+		e := NewEvent(testCWEvent(fmt.Sprint(ix), "s", messages[ix], base.Add(time.Duration(ix)*time.Second).UnixMilli()), "g")
+		events = append(events, e)
+	}
+
+	if got := fmt.Sprint(MovingAverage(events, "latency", 2)); got != "[5 4 5]" {
+		t.Errorf("MovingAverage(2) = %s, want [5 4 5]", got)
+	}
+	if got := fmt.Sprint(MovingAverage(events, "latency", 4)); got != "[5]" {
+		t.Errorf("MovingAverage(4) = %s, want [5]", got)
+	}
+	if got := MovingAverage(events, "latency", 5); got == nil || len(got) != 0 {
+		t.Errorf("Expected no averages with fewer values than the window, got %v", got)
+	}
+	if got := MovingAverage(events, "latency", 0); got != nil {
+		t.Errorf("Expected nil for an invalid window, got %v", got)
+	}
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	var events []Event
+	for ix, latency := range []string{`5`, `"12ms"`, `"10ms"`, `"49.5"`, `"2s"`, `-1`, `"slow"`} {
+		message := fmt.Sprintf(`{"msg":"served","latency":%s}`, latency)
+		events = append(events, NewEvent(testCWEvent(fmt.Sprint(ix), "s", message, 1), "g"))
+	}
+	events = append(events, NewEvent(testCWEvent("none", "s", `{"msg":"no latency"}`, 1), "g"))
+
+	// bounds are sorted, so they can be given in any order
+	buckets := LatencyHistogram(events, "latency", []time.Duration{50 * time.Millisecond, 10 * time.Millisecond})
+	want := []HistogramBucket{
+		{Lower: 0, Upper: 10 * time.Millisecond, Count: 2},
+		{Lower: 10 * time.Millisecond, Upper: 50 * time.Millisecond, Count: 3},
+		{Lower: 50 * time.Millisecond, Overflow: true, Count: 1},
+	}
+	if fmt.Sprint(buckets) != fmt.Sprint(want) {
+		t.Errorf("LatencyHistogram = %+v, want %+v", buckets, want)
+	}
+}
+
+func TestEventDuration(t *testing.T) {
+	e := NewEvent(testCWEvent("1", "s", `{"msg":"m","number":1.5,"string":"250","duration":"1m30s","word":"fast"}`, 1), "g")
+	tests := []struct {
+		key  string
+		want time.Duration
+		ok   bool
+	}{
+		{"number", 1500 * time.Microsecond, true},
+		{"string", 250 * time.Millisecond, true},
+		{"duration", 90 * time.Second, true},
+		{"word", 0, false},
+		{"missing", 0, false},
+	}
+	for _, test := range tests {
+		if got, ok := e.Duration(test.key); got != test.want || ok != test.ok {
+			t.Errorf("Duration(%s) = %s, %v, want %s, %v", test.key, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestSilentStreams(t *testing.T) {
+	previous := []Event{{Stream: "c"}, {Stream: "a"}, {Stream: "b"}, {Stream: "a"}}
+	current := []Event{{Stream: "b"}, {Stream: "d"}}
+
+	if got := fmt.Sprint(SilentStreams(previous, current)); got != "[a c]" {
+		t.Errorf("SilentStreams = %s, want [a c]", got)
+	}
+	if got := SilentStreams(previous, previous); len(got) != 0 {
+		t.Errorf("Expected no silent streams, got %v", got)
+	}
+}
+
+func TestIngestLagPercentiles(t *testing.T) {
+	base := time.Unix(60000, 0)
+	var events []Event
+	// lags of 1 to 10 seconds, given out of order
+	for _, seconds := range []int{7, 2, 10, 1, 5, 3, 9, 4, 8, 6} {
+		lag := time.Duration(seconds) * time.Second
+		events = append(events, Event{CreationTime: base, IngestTime: base.Add(lag)})
+	}
+	events = append(events, Event{CreationTime: base}, Event{IngestTime: base})
+
+	percentiles := IngestLagPercentiles(events, []float64{0, 50, 90, 99, 100})
+	want := map[float64]time.Duration{
+		0:   time.Second,
+		50:  5 * time.Second,
+		90:  9 * time.Second,
+		99:  10 * time.Second,
+		100: 10 * time.Second,
+	}
+	if fmt.Sprint(percentiles) != fmt.Sprint(want) {
+		t.Errorf("IngestLagPercentiles = %v, want %v", percentiles, want)
+	}
+
+	if got := IngestLagPercentiles(events[10:], []float64{50}); len(got) != 0 {
+		t.Errorf("Expected no percentiles without lags, got %v", got)
+	}
+}
@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// Summary holds counts describing a set of events
+type Summary struct {
+	Total   int
+	Levels  map[ecslogs.Level]int
+	Streams map[string]int
+	First   time.Time
+	Last    time.Time
+
+	// ShowZeroLevels includes levels without any events in StatusLine
+	ShowZeroLevels bool
+}
+
+// Summarize counts events by level and stream, and finds the window of time
+// they were created in
+func Summarize(events []Event) Summary {
+	summary := Summary{
+		Total:   len(events),
+		Levels:  map[ecslogs.Level]int{},
+		Streams: map[string]int{},
+	}
+	for ix, e := range events {
+		e = e.Parsed()
+		summary.Levels[e.Level]++
+		summary.Streams[e.Stream]++
+		if ix == 0 || e.CreationTime.Before(summary.First) {
+			summary.First = e.CreationTime
+		}
+		if ix == 0 || e.CreationTime.After(summary.Last) {
+			summary.Last = e.CreationTime
+		}
+	}
+	return summary
+}
+
+// statusLevels are the groups of levels shown by StatusLine, in order
+var statusLevels = []struct {
+	label  string
+	levels []ecslogs.Level
+	color  func(...interface{}) string
+}{
+	{"E", []ecslogs.Level{ecslogs.EMERG, ecslogs.ALERT, ecslogs.CRIT, ecslogs.ERROR}, Red},
+	{"W", []ecslogs.Level{ecslogs.WARN}, Yellow},
+	{"N", []ecslogs.Level{ecslogs.NOTICE}, Cyan},
+	{"I", []ecslogs.Level{ecslogs.INFO}, Green},
+	{"D", []ecslogs.Level{ecslogs.DEBUG}, Blue},
+}
+
+// StatusLine renders the level counts compactly, e.g. "E:3 W:12 I:540", with
+// errors (and anything more severe) counted under E.  If colored is set, each
+// count is colored by its severity.
+func (s Summary) StatusLine(colored bool) string {
+	parts := []string{}
+	for _, status := range statusLevels {
+		count := 0
+		for _, l := range status.levels {
+			count += s.Levels[l]
+		}
+		if count == 0 && !s.ShowZeroLevels {
+			continue
+		}
+
+		part := fmt.Sprintf("%s:%d", status.label, count)
+		if colored {
+			part = status.color(part)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,52 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func TestSummarize(t *testing.T) {
+	base := time.Unix(60000, 0)
+	events := []Event{
+		{Stream: "a", CreationTime: base.Add(time.Second), SlogEvent: SlogEvent{Level: ecslogs.ERROR}},
+		{Stream: "a", CreationTime: base, SlogEvent: SlogEvent{Level: ecslogs.CRIT}},
+		{Stream: "b", CreationTime: base.Add(time.Minute), SlogEvent: SlogEvent{Level: ecslogs.INFO}},
+	}
+
+	summary := Summarize(events)
+	if summary.Total != 3 || summary.Streams["a"] != 2 || summary.Streams["b"] != 1 {
+		t.Errorf("Unexpected counts %+v", summary)
+	}
+	if !summary.First.Equal(base) || !summary.Last.Equal(base.Add(time.Minute)) {
+		t.Errorf("Window = %s to %s, want %s to %s", summary.First, summary.Last, base, base.Add(time.Minute))
+	}
+}
+
+func TestStatusLine(t *testing.T) {
+	noColor := color.NoColor
+	defer func() { color.NoColor = noColor }()
+
+	summary := Summarize(levelEvents(ecslogs.ERROR, ecslogs.CRIT, ecslogs.WARN, ecslogs.INFO, ecslogs.INFO))
+	if got := summary.StatusLine(false); got != "E:2 W:1 I:2" {
+		t.Errorf("StatusLine(false) = %q, want \"E:2 W:1 I:2\"", got)
+	}
+
+	summary.ShowZeroLevels = true
+	if got := summary.StatusLine(false); got != "E:2 W:1 N:0 I:2 D:0" {
+		t.Errorf("StatusLine(false) with zero levels = %q", got)
+	}
+
+	color.NoColor = false
+	summary.ShowZeroLevels = false
+	colored := summary.StatusLine(true)
+	if !strings.Contains(colored, "\x1b[31mE:2") || !strings.Contains(colored, "\x1b[33mW:1") || !strings.Contains(colored, "\x1b[32mI:2") {
+		t.Errorf("Expected counts colored by severity, got %q", colored)
+	}
+	if plain := summary.StatusLine(false); strings.Contains(plain, "\x1b[") {
+		t.Errorf("Expected no colors when not colored, got %q", plain)
+	}
+}
@@ -0,0 +1,152 @@
+package lib
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// syslogLevels maps RFC5424 severities (the index) to log levels
+var syslogLevels = [...]ecslogs.Level{
+	ecslogs.EMERG,
+	ecslogs.ALERT,
+	ecslogs.CRIT,
+	ecslogs.ERROR,
+	ecslogs.WARN,
+	ecslogs.NOTICE,
+	ecslogs.INFO,
+	ecslogs.DEBUG,
+}
+
+// syslogFacilities maps RFC5424 facility codes (the index) to their names
+var syslogFacilities = [...]string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// syslogNil is the RFC5424 NILVALUE, used for header fields with no value
+const syslogNil = "-"
+
+// ParseSyslog parses messages in the RFC5424 syslog format:
+//
+//	<priority>version timestamp hostname app-name procid msgid structured-data msg
+//
+// The severity portion of the priority sets the event level, and the
+// remaining header fields and structured data params are placed in Data.
+func ParseSyslog(stream, message string) (SlogEvent, bool) {
+	if !strings.HasPrefix(message, "<") {
+		return SlogEvent{}, false
+	}
+	end := strings.IndexByte(message, '>')
+	if end < 2 || end > 4 {
+		return SlogEvent{}, false
+	}
+	priority, err := strconv.Atoi(message[1:end])
+	if err != nil || priority < 0 || priority >= len(syslogFacilities)*len(syslogLevels) {
+		return SlogEvent{}, false
+	}
+
+	header := strings.SplitN(message[end+1:], " ", 7)
+	if len(header) < 6 {
+		return SlogEvent{}, false
+	}
+	if version, err := strconv.Atoi(header[0]); err != nil || version < 1 {
+		return SlogEvent{}, false
+	}
+
+	event := SlogEvent{
+		Level: syslogLevels[priority%len(syslogLevels)],
+		Data: map[string]interface{}{
+			"facility": syslogFacilities[priority/len(syslogLevels)],
+		},
+	}
+
+	if header[1] != syslogNil {
+		if event.Time, err = time.Parse(time.RFC3339Nano, header[1]); err != nil {
+			return SlogEvent{}, false
+		}
+	}
+
+	for ix, key := range []string{"hostname", "app_name", "procid", "msgid"} {
+		if value := header[ix+2]; value != syslogNil {
+			event.Data[key] = value
+		}
+	}
+
+	rest := ""
+	if len(header) == 7 {
+		rest = header[6]
+	}
+	rest, ok := parseStructuredData(rest, event.Data)
+	if !ok {
+		return SlogEvent{}, false
+	}
+	event.Message = strings.TrimPrefix(strings.TrimPrefix(rest, " "), "\ufeff")
+
+	return event, true
+}
+
+// parseStructuredData consumes the structured data elements at the start of s,
+// placing each param in data keyed as "<sd-id>.<param-name>", and returns the
+// remainder of s
+func parseStructuredData(s string, data map[string]interface{}) (string, bool) {
+	if s == "" {
+		return s, true
+	}
+	if strings.HasPrefix(s, syslogNil) {
+		return s[len(syslogNil):], true
+	}
+	if !strings.HasPrefix(s, "[") {
+		return s, false
+	}
+
+	for strings.HasPrefix(s, "[") {
+		s = s[1:]
+		idEnd := strings.IndexAny(s, " ]")
+		if idEnd < 1 {
+			return s, false
+		}
+		id := s[:idEnd]
+		s = s[idEnd:]
+
+		for strings.HasPrefix(s, " ") {
+			s = s[1:]
+			eq := strings.Index(s, `="`)
+			if eq < 1 {
+				return s, false
+			}
+			name := s[:eq]
+			s = s[eq+2:]
+
+			var value strings.Builder
+			closed := false
+			for ix := 0; ix < len(s); ix++ {
+				if s[ix] == '\\' && ix+1 < len(s) && strings.IndexByte(`"\]`, s[ix+1]) >= 0 {
+					ix++
+					value.WriteByte(s[ix])
+					continue
+				}
+				if s[ix] == '"' {
+					s = s[ix+1:]
+					closed = true
+					break
+				}
+				value.WriteByte(s[ix])
+			}
+			if !closed {
+				return s, false
+			}
+			data[id+"."+name] = value.String()
+		}
+
+		if !strings.HasPrefix(s, "]") {
+			return s, false
+		}
+		s = s[1:]
+	}
+
+	return s, true
+}
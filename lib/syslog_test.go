@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func TestParseSyslog(t *testing.T) {
+	e, ok := ParseSyslog("s", `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Appli\"cation" eventID="1011"][origin ip="10.0.0.1"] An application event`)
+	if !ok {
+		t.Fatal("Failed to parse an RFC5424 message")
+	}
+	if e.Level != ecslogs.NOTICE {
+		t.Errorf("Level = %s, want %s", e.Level, ecslogs.NOTICE)
+	}
+	if want := time.Date(2003, 10, 11, 22, 14, 15, 3e6, time.UTC); !e.Time.Equal(want) {
+		t.Errorf("Time = %s, want %s", e.Time, want)
+	}
+	if e.Message != "An application event" {
+		t.Errorf("Message = %q", e.Message)
+	}
+	want := map[string]interface{}{
+		"facility":                      "local4",
+		"hostname":                      "mymachine.example.com",
+		"app_name":                      "evntslog",
+		"msgid":                         "ID47",
+		"exampleSDID@32473.iut":         "3",
+		"exampleSDID@32473.eventSource": `Appli"cation`,
+		"exampleSDID@32473.eventID":     "1011",
+		"origin.ip":                     "10.0.0.1",
+	}
+	if len(e.Data) != len(want) {
+		t.Errorf("Data = %v, want %v", e.Data, want)
+	}
+	for key, value := range want {
+		if e.Data[key] != value {
+			t.Errorf("Data[%s] = %v, want %v", key, e.Data[key], value)
+		}
+	}
+
+	e, ok = ParseSyslog("s", `<34>1 - host app 12 - - failed`)
+	if !ok || e.Level != ecslogs.CRIT || e.Message != "failed" || e.Data["facility"] != "auth" || e.Data["procid"] != "12" {
+		t.Errorf("Unexpected event %v %+v", ok, e)
+	}
+
+	for _, message := range []string{
+		"hello",
+		"<34> not syslog",
+		"<999>1 - host app - - - too high a priority",
+		`<34>1 - host app - - [unterminated a="b] message`,
+	} {
+		if _, ok := ParseSyslog("s", message); ok {
+			t.Errorf("Parsed %q as syslog", message)
+		}
+	}
+}
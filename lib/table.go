@@ -0,0 +1,108 @@
+package lib
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// TablePageBreak is the line WriteTable writes between pages.  It is a form
+// feed, which pagers such as less show as ^L.
+const TablePageBreak = "\f"
+
+var (
+	// MaxTableCellWidth is the widest a cell written by WriteTable can be
+	// before it is truncated
+	MaxTableCellWidth = 40
+)
+
+// SetMaxTableCellWidth sets the width at which table cells are truncated
+func SetMaxTableCellWidth(width int) {
+	MaxTableCellWidth = width
+}
+
+// WriteTable writes events to w as a table with aligned columns.  The columns
+// "time", "level", "stream", "group", "id" and "msg" are the event's own
+// fields, and any other column is a (possibly dotted) data field.  Cells wider
+// than MaxTableCellWidth are truncated, and runs of whitespace, including
+// line breaks, become single spaces.  If pageSize is positive, a
+// TablePageBreak line and the header are repeated after every pageSize rows.
+func WriteTable(w io.Writer, events []Event, columns []string, pageSize int) error {
+	header := make([]string, len(columns))
+	widths := make([]int, len(columns))
+	for ix, column := range columns {
+		header[ix] = tableCell(strings.ToUpper(column))
+		widths[ix] = utf8.RuneCountInString(header[ix])
+	}
+
+	rows := make([][]string, len(events))
+	for ix, e := range events {
+		e = e.Parsed()
+		row := make([]string, len(columns))
+		for jx, column := range columns {
+			row[jx] = tableCell(tableValue(e, column))
+			if width := utf8.RuneCountInString(row[jx]); width > widths[jx] {
+				widths[jx] = width
+			}
+		}
+		rows[ix] = row
+	}
+
+	out := bufio.NewWriter(w)
+	writeTableRow(out, header, widths)
+	for ix, row := range rows {
+		if pageSize > 0 && ix > 0 && ix%pageSize == 0 {
+			out.WriteString(TablePageBreak + "\n")
+			writeTableRow(out, header, widths)
+		}
+		writeTableRow(out, row, widths)
+	}
+	return out.Flush()
+}
+
+func tableValue(e Event, column string) string {
+	switch column {
+	case "time":
+		return e.TimeShort()
+	case "level":
+		return LevelName(e.Level)
+	case "stream":
+		return e.Stream
+	case "group":
+		return e.Group
+	case "id":
+		return e.ID
+	case "msg":
+		return e.Message
+	}
+	if v, ok := e.DataValue(column); ok {
+		return dataString(v)
+	}
+	return ""
+}
+
+// tableCell makes s fit on one line of a table, truncating it to
+// MaxTableCellWidth
+func tableCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if MaxTableCellWidth <= 0 || utf8.RuneCountInString(s) <= MaxTableCellWidth {
+		return s
+	}
+	if MaxTableCellWidth == 1 {
+		return "…"
+	}
+	runes := []rune(s)
+	return string(runes[:MaxTableCellWidth-1]) + "…"
+}
+
+func writeTableRow(out *bufio.Writer, cells []string, widths []int) {
+	for ix, cell := range cells {
+		out.WriteString(cell)
+		// the last column isn't padded, so lines have no trailing spaces
+		if ix < len(cells)-1 {
+			out.WriteString(strings.Repeat(" ", widths[ix]-utf8.RuneCountInString(cell)+2))
+		}
+	}
+	out.WriteString("\n")
+}
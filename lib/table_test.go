@@ -0,0 +1,39 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteTable(t *testing.T) {
+	var events []Event
+	for ix, message := range []string{"a", "bbbbbb", "multi\nline", strings.Repeat("x", 100), "e"} {
+		events = append(events, Event{
+			Stream:    "s" + string(rune('0'+ix)),
+			SlogEvent: SlogEvent{Message: message, Data: map[string]interface{}{"request": map[string]interface{}{"status": "200"}}},
+		})
+	}
+
+	var out strings.Builder
+	if err := WriteTable(&out, events, []string{"stream", "request.status", "msg"}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	header := "STREAM  REQUEST.STATUS  MSG"
+	want := strings.Join([]string{
+		header,
+		"s0      200             a",
+		"s1      200             bbbbbb",
+		TablePageBreak,
+		header,
+		"s2      200             multi line",
+		"s3      200             " + strings.Repeat("x", MaxTableCellWidth-1) + "…",
+		TablePageBreak,
+		header,
+		"s4      200             e",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Errorf("Table =\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,136 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+var (
+	uuidPattern   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	ipPattern     = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`)
+	quotedPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`)
+	hexPattern    = regexp.MustCompile(`\b(?:0x[0-9a-fA-F]+|[0-9a-fA-F]{8,})\b`)
+	numberPattern = regexp.MustCompile(`\b\d+(?:\.\d+)?`)
+)
+
+// MessageTemplate normalizes a message into a template by replacing the parts
+// that vary between otherwise identical messages (UUIDs, IPs, quoted strings,
+// hex IDs and numbers) with placeholders, e.g. "took 12ms for user 42" becomes
+// "took <num>ms for user <num>"
+func MessageTemplate(message string) string {
+	message = uuidPattern.ReplaceAllString(message, "<uuid>")
+	message = ipPattern.ReplaceAllString(message, "<ip>")
+	message = quotedPattern.ReplaceAllString(message, "<str>")
+	message = hexPattern.ReplaceAllStringFunc(message, func(s string) string {
+		// leave plain numbers and hex-like words for the checks below
+		if strings.HasPrefix(s, "0x") || (strings.ContainsAny(s, "0123456789") && strings.ContainsAny(s, "abcdefABCDEF")) {
+			return "<hex>"
+		}
+		return s
+	})
+	return numberPattern.ReplaceAllString(message, "<num>")
+}
+
+// TemplateCount is the number of events with messages matching a template
+type TemplateCount struct {
+	Template string
+	Count    int
+}
+
+// TopTemplates returns the n most common message templates among events, most
+// common first
+func TopTemplates(events []Event, n int) []TemplateCount {
+	counts := map[string]int{}
+	for _, e := range events {
+		counts[MessageTemplate(e.Parsed().Message)]++
+	}
+	return topTemplateCounts(counts, n)
+}
+
+// TopTemplatesByLevel returns the n most common message templates within each
+// log level, most common first
+func TopTemplatesByLevel(events []Event, n int) map[ecslogs.Level][]TemplateCount {
+	counts := map[ecslogs.Level]map[string]int{}
+	for _, e := range events {
+		e = e.Parsed()
+		if counts[e.Level] == nil {
+			counts[e.Level] = map[string]int{}
+		}
+		counts[e.Level][MessageTemplate(e.Message)]++
+	}
+
+	top := make(map[ecslogs.Level][]TemplateCount, len(counts))
+	for level, levelCounts := range counts {
+		top[level] = topTemplateCounts(levelCounts, n)
+	}
+	return top
+}
+
+// topTemplateCounts ranks templates by count, breaking ties alphabetically
+func topTemplateCounts(counts map[string]int, n int) []TemplateCount {
+	ranked := make([]TemplateCount, 0, len(counts))
+	for template, count := range counts {
+		ranked = append(ranked, TemplateCount{Template: template, Count: count})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].Template < ranked[j].Template
+	})
+
+	if n >= 0 && len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// DiffMessages compares the message templates of two sets of events, such as
+// the logs of two deploys, in the style of a unified diff.  Templates only
+// found in a are listed with a "-" prefix and templates only found in b with
+// a "+" prefix, then the number of events with the template, sorted by
+// template.
+func DiffMessages(a, b []Event) string {
+	countsA := map[string]int{}
+	for _, e := range a {
+		countsA[MessageTemplate(e.Parsed().Message)]++
+	}
+	countsB := map[string]int{}
+	for _, e := range b {
+		countsB[MessageTemplate(e.Parsed().Message)]++
+	}
+
+	type change struct {
+		sign     string
+		template string
+		count    int
+	}
+	var changes []change
+	for template, count := range countsA {
+		if _, ok := countsB[template]; !ok {
+			changes = append(changes, change{"-", template, count})
+		}
+	}
+	for template, count := range countsB {
+		if _, ok := countsA[template]; !ok {
+			changes = append(changes, change{"+", template, count})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].template != changes[j].template {
+			return changes[i].template < changes[j].template
+		}
+		return changes[i].sign < changes[j].sign
+	})
+
+	var out strings.Builder
+	out.WriteString("--- a\n+++ b\n")
+	for _, c := range changes {
+		fmt.Fprintf(&out, "%s%d %s\n", c.sign, c.count, c.template)
+	}
+	return out.String()
+}
@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+func TestMessageTemplate(t *testing.T) {
+	tests := map[string]string{
+		"took 12ms for user 42":                               "took <num>ms for user <num>",
+		"conn from 10.0.0.1:443 id deadbeef12":                "conn from <ip> id <hex>",
+		`user "bob" req 3fa85f64-5717-4562-b3fc-2c963f66afa6`: "user <str> req <uuid>",
+		"error connecting to db":                              "error connecting to db",
+	}
+	for message, want := range tests {
+		if got := MessageTemplate(message); got != want {
+			t.Errorf("MessageTemplate(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
+
+// testMessageEvents builds an event with each message
+func testMessageEvents(messages ...string) []Event {
+	events := make([]Event, len(messages))
+	for ix, message := range messages {
+		events[ix] = Event{SlogEvent: SlogEvent{Message: message}}
+	}
+	return events
+}
+
+func TestDiffMessages(t *testing.T) {
+	a := testMessageEvents("took 12ms", "took 30ms", "cache miss for 7", "starting")
+	b := testMessageEvents("took 5ms", "db timeout after 3 retries", "db timeout after 5 retries", "starting")
+
+	want := "--- a\n+++ b\n" +
+		"-1 cache miss for <num>\n" +
+		"+2 db timeout after <num> retries\n"
+	if got := DiffMessages(a, b); got != want {
+		t.Errorf("DiffMessages = %q, want %q", got, want)
+	}
+}
+
+func TestDiffMessagesLazy(t *testing.T) {
+	SetLazyParsing(true)
+	defer SetLazyParsing(false)
+
+	a := []Event{NewEvent(testCWEvent("1", "s", `{"msg":"old message"}`, 0), "group")}
+	b := []Event{NewEvent(testCWEvent("2", "s", `{"msg":"new message"}`, 0), "group")}
+
+	want := "--- a\n+++ b\n+1 new message\n-1 old message\n"
+	if got := DiffMessages(a, b); got != want {
+		t.Errorf("DiffMessages = %q, want %q", got, want)
+	}
+}
+
+func TestTopTemplatesByLevel(t *testing.T) {
+	var events []Event
+	add := func(level ecslogs.Level, messages ...string) {
+		for _, e := range testMessageEvents(messages...) {
+			e.Level = level
+			events = append(events, e)
+		}
+	}
+	add(ecslogs.ERROR, "timeout after 5s", "timeout after 10s", "disk full", "bad id 1", "bad id 2")
+	add(ecslogs.INFO, "served in 3ms", "served in 4ms", "served in 5ms", "started")
+
+	top := TopTemplatesByLevel(events, 2)
+	if len(top) != 2 {
+		t.Fatalf("Expected templates for 2 levels, got %v", top)
+	}
+	// ties are broken alphabetically
+	if got := fmt.Sprint(top[ecslogs.ERROR]); got != "[{bad id <num> 2} {timeout after <num>s 2}]" {
+		t.Errorf("Top error templates = %s", got)
+	}
+	if got := fmt.Sprint(top[ecslogs.INFO]); got != "[{served in <num>ms 3} {started 1}]" {
+		t.Errorf("Top info templates = %s", got)
+	}
+	if all := TopTemplatesByLevel(events, -1); len(all[ecslogs.ERROR]) != 3 {
+		t.Errorf("Expected every error template without a limit, got %v", all[ecslogs.ERROR])
+	}
+}
@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimePrecision controls how writers render timestamps
+type TimePrecision int
+
+// Timestamp precisions.  Epoch precisions are written as integers, and the
+// others as strings.
+const (
+	// DefaultPrecision uses each writer's own format
+	DefaultPrecision TimePrecision = iota
+	SecondsPrecision
+	MillisPrecision
+	NanosPrecision
+	RFC3339Precision
+	RFC3339NanoPrecision
+)
+
+var (
+	// OutputTimePrecision is the precision used by the JSON lines, CSV and
+	// OpenSearch writers for timestamps
+	OutputTimePrecision = DefaultPrecision
+)
+
+// SetTimePrecision sets the precision writers use for timestamps
+func SetTimePrecision(p TimePrecision) {
+	OutputTimePrecision = p
+}
+
+// ParseTimePrecision parses the name of a precision (seconds, millis, nanos,
+// rfc3339, rfc3339nano or default)
+func ParseTimePrecision(name string) (TimePrecision, error) {
+	switch strings.ToLower(name) {
+	case "", "default":
+		return DefaultPrecision, nil
+	case "seconds", "s":
+		return SecondsPrecision, nil
+	case "millis", "ms":
+		return MillisPrecision, nil
+	case "nanos", "ns":
+		return NanosPrecision, nil
+	case "rfc3339":
+		return RFC3339Precision, nil
+	case "rfc3339nano":
+		return RFC3339NanoPrecision, nil
+	default:
+		return DefaultPrecision, fmt.Errorf("Unknown time precision '%s'", name)
+	}
+}
+
+// formatTime renders t at precision p, using defaultLayout for the default
+// precision.  Epoch precisions give an int64, the others a string.
+func (p TimePrecision) formatTime(t time.Time, defaultLayout string) interface{} {
+	switch p {
+	case SecondsPrecision:
+		return t.Unix()
+	case MillisPrecision:
+		return t.UnixNano() / int64(time.Millisecond)
+	case NanosPrecision:
+		return t.UnixNano()
+	case RFC3339Precision:
+		return t.Format(time.RFC3339)
+	case RFC3339NanoPrecision:
+		return t.Format(time.RFC3339Nano)
+	default:
+		return t.Format(defaultLayout)
+	}
+}
+
+// outputTime is a timestamp which is written at OutputTimePrecision, and can
+// be read back from any precision
+type outputTime struct {
+	time.Time
+}
+
+func (t outputTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(OutputTimePrecision.formatTime(t.Time, time.RFC3339Nano))
+}
+
+// UnmarshalJSON reads RFC3339 strings, or epoch numbers in seconds, millis or
+// nanos (chosen by magnitude)
+func (t *outputTime) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &t.Time)
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return err
+	}
+
+	abs := i
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e11:
+		t.Time = time.Unix(i, 0)
+	case abs < 1e14:
+		t.Time = time.Unix(0, i*int64(time.Millisecond))
+	default:
+		t.Time = time.Unix(0, i)
+	}
+	return nil
+}
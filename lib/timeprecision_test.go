@@ -0,0 +1,87 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimePrecision(t *testing.T) {
+	defer SetTimePrecision(DefaultPrecision)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	e := Event{ID: "1", CreationTime: ts, IngestTime: ts, SlogEvent: SlogEvent{Time: ts, Message: "m"}}
+	tests := []struct {
+		precision TimePrecision
+		json      string
+		csv       string
+		readBack  time.Time
+	}{
+		{DefaultPrecision, `"2024-01-02T03:04:05.123456789Z"`, "2024-01-02T03:04:05.123456789Z", ts},
+		{SecondsPrecision, `1704164645`, "1704164645", ts.Truncate(time.Second)},
+		{MillisPrecision, `1704164645123`, "1704164645123", ts.Truncate(time.Millisecond)},
+		{NanosPrecision, `1704164645123456789`, "1704164645123456789", ts},
+		{RFC3339Precision, `"2024-01-02T03:04:05Z"`, "2024-01-02T03:04:05Z", ts.Truncate(time.Second)},
+		{RFC3339NanoPrecision, `"2024-01-02T03:04:05.123456789Z"`, "2024-01-02T03:04:05.123456789Z", ts},
+	}
+	for _, test := range tests {
+		SetTimePrecision(test.precision)
+
+		var out bytes.Buffer
+		if err := WriteJSONL(&out, []Event{e}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), `"time":`+test.json) {
+			t.Errorf("precision %d: JSON line %s, want time %s", test.precision, out.String(), test.json)
+		}
+		read, err := ReadJSONL(&out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !read[0].Time.Equal(test.readBack) || !read[0].CreationTime.Equal(test.readBack) {
+			t.Errorf("precision %d: read back %s, want %s", test.precision, read[0].Time, test.readBack)
+		}
+
+		out.Reset()
+		if err := WriteCSV(&out, []Event{e}); err != nil {
+			t.Fatal(err)
+		}
+		records, err := csv.NewReader(&out).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if records[1][4] != test.csv {
+			t.Errorf("precision %d: CSV time %s, want %s", test.precision, records[1][4], test.csv)
+		}
+
+		out.Reset()
+		if err := WriteOpenSearch(&out, []Event{e}, "logs"); err != nil {
+			t.Fatal(err)
+		}
+		want := test.json
+		if test.precision == DefaultPrecision {
+			want = `"2024-01-02T03:04:05.123Z"`
+		}
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal(bytes.Split(out.Bytes(), []byte("\n"))[1], &doc); err != nil {
+			t.Fatal(err)
+		}
+		if string(doc["@timestamp"]) != want {
+			t.Errorf("precision %d: OpenSearch @timestamp %s, want %s", test.precision, doc["@timestamp"], want)
+		}
+	}
+}
+
+func TestParseTimePrecision(t *testing.T) {
+	for name, want := range map[string]TimePrecision{"": DefaultPrecision, "ms": MillisPrecision, "RFC3339Nano": RFC3339NanoPrecision} {
+		if got, err := ParseTimePrecision(name); err != nil || got != want {
+			t.Errorf("ParseTimePrecision(%q) = %d, %v, want %d", name, got, err, want)
+		}
+	}
+	if _, err := ParseTimePrecision("hours"); err == nil {
+		t.Error("Expected an error for an unknown precision")
+	}
+}
@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"sort"
+)
+
+// Data fields used by BuildTraceTree to relate events to each other
+const (
+	TraceIDKey      = "trace_id"
+	SpanIDKey       = "span_id"
+	ParentSpanIDKey = "parent_span_id"
+)
+
+// TraceNode is an event within a trace and the events of its child spans.  The
+// root node of each trace has no event, only the trace's ID.
+type TraceNode struct {
+	TraceID  string
+	Event    Event
+	Children []TraceNode
+}
+
+// traceSpan is a node being built by BuildTraceTree
+type traceSpan struct {
+	event    Event
+	parent   *traceSpan
+	children []*traceSpan
+}
+
+// descendsFrom reports whether s is ancestor or one of its descendants, so that
+// spans naming each other as parents don't form a cycle
+func (s *traceSpan) descendsFrom(ancestor *traceSpan) bool {
+	for ; s != nil; s = s.parent {
+		if s == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildTraceTree reconstructs traces from events carrying trace_id, span_id
+// and parent_span_id data fields, returning a root node for each trace in
+// order of its first event.  Events are attached to the event of their parent
+// span, or to the trace's root if they have no parent or their parent wasn't
+// logged.  Children are ordered by time.  Events without a trace_id are left
+// out.
+func BuildTraceTree(events []Event) []TraceNode {
+	sorted := []Event{}
+	for _, e := range events {
+		e = e.Parsed()
+		if _, ok := e.DataValue(TraceIDKey); ok {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var traceIDs []string
+	roots := map[string]*traceSpan{}
+	spans := map[string]map[string]*traceSpan{}
+	all := make([]*traceSpan, len(sorted))
+	for ix, e := range sorted {
+		all[ix] = &traceSpan{event: e}
+		traceID := traceField(e, TraceIDKey)
+		if _, ok := roots[traceID]; !ok {
+			traceIDs = append(traceIDs, traceID)
+			roots[traceID] = &traceSpan{}
+			spans[traceID] = map[string]*traceSpan{}
+		}
+		// if a span logged more than once, children attach to its first event
+		if spanID := traceField(e, SpanIDKey); spanID != "" {
+			if _, ok := spans[traceID][spanID]; !ok {
+				spans[traceID][spanID] = all[ix]
+			}
+		}
+	}
+
+	for _, span := range all {
+		traceID := traceField(span.event, TraceIDKey)
+		parent, ok := spans[traceID][traceField(span.event, ParentSpanIDKey)]
+		if !ok || parent.descendsFrom(span) {
+			parent = roots[traceID]
+		}
+		span.parent = parent
+		parent.children = append(parent.children, span)
+	}
+
+	nodes := make([]TraceNode, len(traceIDs))
+	for ix, traceID := range traceIDs {
+		nodes[ix] = TraceNode{
+			TraceID:  traceID,
+			Children: traceChildren(traceID, roots[traceID].children),
+		}
+	}
+	return nodes
+}
+
+func traceChildren(traceID string, spans []*traceSpan) []TraceNode {
+	if len(spans) == 0 {
+		return nil
+	}
+	nodes := make([]TraceNode, len(spans))
+	for ix, span := range spans {
+		nodes[ix] = TraceNode{
+			TraceID:  traceID,
+			Event:    span.event,
+			Children: traceChildren(traceID, span.children),
+		}
+	}
+	return nodes
+}
+
+func traceField(e Event, key string) string {
+	v, ok := e.DataValue(key)
+	if !ok {
+		return ""
+	}
+	return dataString(v)
+}
@@ -0,0 +1,57 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// traceOutline renders the messages of a trace's events, indented by depth
+func traceOutline(nodes []TraceNode, depth int) string {
+	var out strings.Builder
+	for _, node := range nodes {
+		fmt.Fprintf(&out, "%s%s\n", strings.Repeat("  ", depth), node.Event.Message)
+		out.WriteString(traceOutline(node.Children, depth+1))
+	}
+	return out.String()
+}
+
+func TestBuildTraceTree(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+	span := func(seconds int, message, traceID, spanID, parentID string) Event {
+		data := map[string]interface{}{TraceIDKey: traceID, SpanIDKey: spanID}
+		if parentID != "" {
+			data[ParentSpanIDKey] = parentID
+		}
+		return Event{SlogEvent: SlogEvent{Time: base.Add(time.Duration(seconds) * time.Second), Message: message, Data: data}}
+	}
+	events := []Event{
+		span(3, "second child", "t", "c2", "r"),
+		span(0, "root", "t", "r", ""),
+		span(1, "first child", "t", "c1", "r"),
+		span(2, "grandchild", "t", "g", "c1"),
+		span(4, "orphan", "t", "o", "missing"),
+		span(5, "other trace", "u", "x", ""),
+		// spans naming each other as parents
+		span(6, "cycle a", "v", "a", "b"),
+		span(7, "cycle b", "v", "b", "a"),
+		{SlogEvent: SlogEvent{Time: base, Message: "untraced"}},
+	}
+
+	traces := BuildTraceTree(events)
+	if len(traces) != 3 || traces[0].TraceID != "t" || traces[1].TraceID != "u" || traces[2].TraceID != "v" {
+		t.Fatalf("Expected traces t, u and v, got %+v", traces)
+	}
+
+	want := "root\n  first child\n    grandchild\n  second child\norphan\n"
+	if got := traceOutline(traces[0].Children, 0); got != want {
+		t.Errorf("Trace t =\n%s\nwant\n%s", got, want)
+	}
+	if got := traceOutline(traces[1].Children, 0); got != "other trace\n" {
+		t.Errorf("Trace u =\n%s", got)
+	}
+	if got := traceOutline(traces[2].Children, 0); got != "cycle b\n  cycle a\n" {
+		t.Errorf("Expected the cycle to be broken, got trace v =\n%s", got)
+	}
+}
@@ -0,0 +1,121 @@
+package lib
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	ecslogs "github.com/segmentio/ecs-logs-go"
+)
+
+// W3CParser parses logs in the W3C extended log file format, as written by IIS
+// and some proxies.  The parser is stateful: the columns of each entry are
+// learned from the most recent #Fields directive seen on the same stream, so
+// that streams from hosts with different layouts can be interleaved.
+//
+// Messages must be parsed in the order they were written, so lazy parsing,
+// which parses events as they're accessed, shouldn't be used with it.
+type W3CParser struct {
+	mu      sync.Mutex
+	streams map[string]*w3cLayout
+}
+
+// w3cLayout is the state of the directives seen on a stream
+type w3cLayout struct {
+	fields []string
+	date   string
+}
+
+// NewW3CParser returns a W3CParser which hasn't seen any directives yet
+func NewW3CParser() *W3CParser {
+	return &W3CParser{streams: map[string]*w3cLayout{}}
+}
+
+// Parse parses a directive or an entry.  Directives are returned as events
+// with the directive name and value in Data, and entries have each of their
+// columns placed in Data by field name, skipping empty ("-") values.  Entries
+// read before a #Fields directive on their stream are not parsed.
+func (p *W3CParser) Parse(stream, message string) (SlogEvent, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	layout, ok := p.streams[stream]
+	if !ok {
+		layout = &w3cLayout{}
+	}
+	if strings.HasPrefix(message, "#") {
+		event, ok := layout.parseDirective(message)
+		if ok {
+			p.streams[stream] = layout
+		}
+		return event, ok
+	}
+	if layout.fields == nil {
+		return SlogEvent{}, false
+	}
+
+	values, ok := splitQuoted(message)
+	if !ok || len(values) != len(layout.fields) {
+		return SlogEvent{}, false
+	}
+
+	event := SlogEvent{
+		Level:   ecslogs.INFO,
+		Message: message,
+		Data:    make(map[string]interface{}, len(values)),
+	}
+	for ix, field := range layout.fields {
+		if values[ix] != "-" {
+			event.Data[field] = values[ix]
+		}
+	}
+
+	date, _ := event.Data["date"].(string)
+	if date == "" {
+		date = layout.date
+	}
+	if clock, ok := event.Data["time"].(string); ok && date != "" {
+		// W3C extended log times are always in UTC
+		if t, err := time.Parse("2006-01-02 15:04:05", date+" "+clock); err == nil {
+			event.Time = t
+		}
+	}
+
+	method, _ := event.Data["cs-method"].(string)
+	uri, _ := event.Data["cs-uri-stem"].(string)
+	if method != "" && uri != "" {
+		event.Message = method + " " + uri
+		if status, ok := event.Data["sc-status"].(string); ok {
+			event.Message += " " + status
+		}
+	}
+
+	return event, true
+}
+
+func (l *w3cLayout) parseDirective(message string) (SlogEvent, bool) {
+	name, value, ok := strings.Cut(strings.TrimPrefix(message, "#"), ":")
+	if !ok {
+		return SlogEvent{}, false
+	}
+	value = strings.TrimSpace(value)
+
+	switch name {
+	case "Fields":
+		l.fields = strings.Fields(value)
+	case "Date":
+		l.date, _, _ = strings.Cut(value, " ")
+	case "Version", "Software", "Start-Date", "End-Date", "Remark":
+	default:
+		return SlogEvent{}, false
+	}
+
+	return SlogEvent{
+		Level:   ecslogs.INFO,
+		Message: message,
+		Data: map[string]interface{}{
+			"directive": name,
+			"value":     value,
+		},
+	}, true
+}
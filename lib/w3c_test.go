@@ -0,0 +1,57 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestW3CParser(t *testing.T) {
+	p := NewW3CParser()
+	if _, ok := p.Parse("a", "2002-05-02 17:42:15 GET /"); ok {
+		t.Fatal("parsed an entry before any #Fields directive")
+	}
+
+	directives := []string{
+		"#Version: 1.0",
+		"#Date: 2002-05-02 17:42:15",
+		"#Fields: date time c-ip cs-method cs-uri-stem sc-status cs(User-Agent)",
+	}
+	for _, line := range directives {
+		if _, ok := p.Parse("a", line); !ok {
+			t.Fatalf("failed to parse directive %q", line)
+		}
+	}
+
+	e, ok := p.Parse("a", "2002-05-02 17:42:15 172.22.255.255 GET /default.htm 200 Mozilla/4.0+(compatible)")
+	if !ok {
+		t.Fatal("failed to parse entry")
+	}
+	if e.Data["c-ip"] != "172.22.255.255" {
+		t.Errorf("c-ip = %v, want 172.22.255.255", e.Data["c-ip"])
+	}
+	if e.Message != "GET /default.htm 200" {
+		t.Errorf("Message = %q, want %q", e.Message, "GET /default.htm 200")
+	}
+	if want := time.Date(2002, 5, 2, 17, 42, 15, 0, time.UTC); !e.Time.Equal(want) {
+		t.Errorf("Time = %s, want %s", e.Time, want)
+	}
+}
+
+func TestW3CParserStreams(t *testing.T) {
+	p := NewW3CParser()
+	p.Parse("host-a", "#Fields: date time cs-method cs-uri-stem sc-status")
+	p.Parse("host-b", "#Fields: time c-ip cs-method")
+
+	// each stream's entries use that stream's layout, however they interleave
+	a, ok := p.Parse("host-a", "2002-05-02 17:42:15 GET /index.htm 200")
+	if !ok || a.Data["sc-status"] != "200" {
+		t.Errorf("host-a entry = %v, %v", a.Data, ok)
+	}
+	b, ok := p.Parse("host-b", "17:42:16 10.0.0.1 POST")
+	if !ok || b.Data["c-ip"] != "10.0.0.1" {
+		t.Errorf("host-b entry = %v, %v", b.Data, ok)
+	}
+	if _, ok := p.Parse("host-c", "17:42:16 10.0.0.1 POST"); ok {
+		t.Error("parsed an entry from a stream without a #Fields directive")
+	}
+}
@@ -0,0 +1,45 @@
+package lib
+
+// WAFInfo holds the details of a request logged by AWS WAF
+type WAFInfo struct {
+	Action   string
+	RuleID   string
+	ClientIP string
+	URI      string
+	Method   string
+	Country  string
+	Headers  map[string]string
+}
+
+// WAFInfo extracts the details of the request from an AWS WAF log event.  It
+// returns false if the event isn't a WAF log.
+func (e Event) WAFInfo() (WAFInfo, bool) {
+	e = e.Parsed()
+	action, _ := e.Data["action"].(string)
+	request, ok := e.Data["httpRequest"].(map[string]interface{})
+	if action == "" || !ok {
+		return WAFInfo{}, false
+	}
+
+	info := WAFInfo{
+		Action:  action,
+		Headers: map[string]string{},
+	}
+	info.RuleID, _ = e.Data["terminatingRuleId"].(string)
+	info.ClientIP, _ = request["clientIp"].(string)
+	info.URI, _ = request["uri"].(string)
+	info.Method, _ = request["httpMethod"].(string)
+	info.Country, _ = request["country"].(string)
+
+	headers, _ := request["headers"].([]interface{})
+	for _, h := range headers {
+		header, _ := h.(map[string]interface{})
+		name, _ := header["name"].(string)
+		value, _ := header["value"].(string)
+		if name != "" {
+			info.Headers[name] = value
+		}
+	}
+
+	return info, true
+}
@@ -0,0 +1,41 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testWAFLog = `{"timestamp":1576280412771,"formatVersion":1,"webaclId":"arn:aws:wafv2:ap-southeast-2:111122223333:regional/webacl/STMTest/1EXAMPLE","terminatingRuleId":"STMTest_SQLi_XSS","terminatingRuleType":"REGULAR","action":"BLOCK","terminatingRuleMatchDetails":[{"conditionType":"SQL_INJECTION","location":"UNKNOWN","matchedData":["10","AND","1"]}],"httpSourceName":"ALB","httpSourceId":"ALB","ruleGroupList":[],"rateBasedRuleList":[],"nonTerminatingMatchingRules":[],"httpRequest":{"clientIp":"1.1.1.1","country":"AU","headers":[{"name":"Host","value":"localhost:1989"},{"name":"User-Agent","value":"curl/7.61.1"}],"uri":"/myUri","args":"","httpVersion":"HTTP/1.1","httpMethod":"GET","requestId":null}}`
+
+func TestWAFInfo(t *testing.T) {
+	for _, lazy := range []bool{false, true} {
+		SetLazyParsing(lazy)
+		e := NewEvent(testCWEvent("1", "s", testWAFLog, 0), "aws-waf-logs-test")
+		info, ok := e.WAFInfo()
+		if !ok {
+			t.Fatalf("lazy=%v: WAFInfo returned false", lazy)
+		}
+
+		want := WAFInfo{
+			Action:   "BLOCK",
+			RuleID:   "STMTest_SQLi_XSS",
+			ClientIP: "1.1.1.1",
+			URI:      "/myUri",
+			Method:   "GET",
+			Country:  "AU",
+		}
+		got := info
+		got.Headers = nil
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("lazy=%v: WAFInfo = %+v, want %+v", lazy, got, want)
+		}
+		if info.Headers["User-Agent"] != "curl/7.61.1" {
+			t.Errorf("lazy=%v: Headers = %v", lazy, info.Headers)
+		}
+	}
+	SetLazyParsing(false)
+
+	if _, ok := NewEvent(testCWEvent("2", "s", `{"msg":"hi"}`, 0), "group").WAFInfo(); ok {
+		t.Error("WAFInfo returned true for a non-WAF event")
+	}
+}
@@ -0,0 +1,88 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Watermark tracks event-time progress for aggregating events into time
+// buckets when events can arrive out of order.  Events are allowed to arrive
+// up to the allowed lateness behind the latest creation time seen, so a bucket
+// is closed, and safe to emit, once the watermark (the latest creation time
+// seen minus the allowed lateness) has passed its end.
+//
+// A Watermark is not safe for concurrent use.
+type Watermark struct {
+	bucket   time.Duration
+	lateness time.Duration
+	maxSeen  time.Time
+	// open are the starts of the open buckets events have been seen in, in
+	// order.  Empty buckets aren't tracked, since there can be far more of
+	// them than events when buckets are small.
+	open []time.Time
+}
+
+// NewWatermark returns a Watermark for buckets of the given size, allowing
+// events to arrive up to allowedLateness out of order.  The bucket size must
+// be positive.
+func NewWatermark(bucket time.Duration, allowedLateness time.Duration) (*Watermark, error) {
+	if bucket <= 0 {
+		return nil, fmt.Errorf("Bucket size must be positive, not %s", bucket)
+	}
+	return &Watermark{
+		bucket:   bucket,
+		lateness: allowedLateness,
+	}, nil
+}
+
+// Observe records an event, and returns the start of each bucket that was
+// closed as a result, in order.  Only buckets which an event arrived in while
+// they were still open are reported, so empty buckets are skipped.
+func (w *Watermark) Observe(e Event) []time.Time {
+	// late events are left out, since their bucket was already reported
+	start := e.CreationTime.Truncate(w.bucket)
+	if !w.IsClosed(start) {
+		ix := sort.Search(len(w.open), func(i int) bool { return !w.open[i].Before(start) })
+		if ix == len(w.open) || !w.open[ix].Equal(start) {
+			w.open = append(w.open, time.Time{})
+			copy(w.open[ix+1:], w.open[ix:])
+			w.open[ix] = start
+		}
+	}
+	if e.CreationTime.After(w.maxSeen) {
+		w.maxSeen = e.CreationTime
+	}
+
+	n := 0
+	for n < len(w.open) && w.IsClosed(w.open[n]) {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	closed := make([]time.Time, n)
+	copy(closed, w.open)
+	w.open = w.open[n:]
+	return closed
+}
+
+// Current returns the watermark.  No more events are expected to arrive from
+// before this time.
+func (w *Watermark) Current() time.Time {
+	if w.maxSeen.IsZero() {
+		return time.Time{}
+	}
+	return w.maxSeen.Add(-w.lateness)
+}
+
+// IsClosed reports whether the bucket starting at bucketStart is closed
+func (w *Watermark) IsClosed(bucketStart time.Time) bool {
+	current := w.Current()
+	return !current.IsZero() && !bucketStart.Add(w.bucket).After(current)
+}
+
+// IsLate reports whether an event arrived after its bucket was closed
+func (w *Watermark) IsLate(e Event) bool {
+	return w.IsClosed(e.CreationTime.Truncate(w.bucket))
+}
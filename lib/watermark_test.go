@@ -0,0 +1,85 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWatermark(t *testing.T) {
+	base := time.Unix(600, 0)
+	at := func(secs int) Event {
+		return Event{CreationTime: base.Add(time.Duration(secs) * time.Second)}
+	}
+
+	w, err := NewWatermark(time.Minute, 30*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed := w.Observe(at(10)); len(closed) != 0 {
+		t.Errorf("closed %v after the first event", closed)
+	}
+	if closed := w.Observe(at(70)); len(closed) != 0 {
+		t.Errorf("closed %v within the allowed lateness", closed)
+	}
+	if w.IsLate(at(50)) {
+		t.Error("event within the allowed lateness is late")
+	}
+	closed := w.Observe(at(95))
+	if len(closed) != 1 || !closed[0].Equal(base) {
+		t.Errorf("closed %v, want [%s]", closed, base)
+	}
+	if !w.IsLate(at(5)) {
+		t.Error("event in a closed bucket isn't late")
+	}
+}
+
+func TestWatermarkOutOfOrder(t *testing.T) {
+	base := time.Unix(600, 0)
+	at := func(secs int) Event {
+		return Event{CreationTime: base.Add(time.Duration(secs) * time.Second)}
+	}
+
+	w, err := NewWatermark(time.Second, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var closed []time.Time
+	for _, secs := range []int{5, 3, 2, 30} {
+		closed = append(closed, w.Observe(at(secs))...)
+	}
+
+	// the buckets with events before the watermark (20s), but not the empty
+	// ones between them
+	want := []time.Time{at(2).CreationTime, at(3).CreationTime, at(5).CreationTime}
+	if fmt.Sprint(closed) != fmt.Sprint(want) {
+		t.Errorf("closed %v, want %v", closed, want)
+	}
+}
+
+func TestWatermarkLargeGap(t *testing.T) {
+	base := time.Unix(600, 0)
+	w, err := NewWatermark(time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Observe(Event{CreationTime: base})
+	// an hour of millisecond buckets, all but one of them empty
+	closed := w.Observe(Event{CreationTime: base.Add(time.Hour)})
+	if len(closed) != 1 || !closed[0].Equal(base) {
+		t.Errorf("closed %d buckets, want only %s", len(closed), base)
+	}
+	closed = w.Observe(Event{CreationTime: base.Add(time.Hour + time.Millisecond)})
+	if len(closed) != 1 || !closed[0].Equal(base.Add(time.Hour)) {
+		t.Errorf("closed %v, want [%s]", closed, base.Add(time.Hour))
+	}
+}
+
+func TestNewWatermarkBucket(t *testing.T) {
+	for _, bucket := range []time.Duration{0, -time.Second} {
+		if _, err := NewWatermark(bucket, time.Second); err == nil {
+			t.Errorf("NewWatermark(%s) didn't return an error", bucket)
+		}
+	}
+}